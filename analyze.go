@@ -0,0 +1,116 @@
+package xmltv
+
+import "time"
+
+// AnalyzeOptions configures TV.Analyze.
+type AnalyzeOptions struct {
+	// MinGap is the shortest gap between programmes that is reported. When zero,
+	// every gap is reported.
+	MinGap time.Duration
+	// MaxLength is the longest a programme may run before it is reported as
+	// suspiciously long. When zero, lengths are not checked.
+	MaxLength time.Duration
+}
+
+// ScheduleReport lists the schedule problems found by TV.Analyze. Programmes are
+// referred to by their index in TV.Programmes.
+type ScheduleReport struct {
+	// Channels holds a report for every channel with at least one problem, in
+	// document order.
+	Channels []ChannelReport `json:"channels"`
+}
+
+// ChannelReport lists the schedule problems of a single channel.
+type ChannelReport struct {
+	Channel  string    `json:"channel"`
+	Overlaps []Overlap `json:"overlaps,omitempty"`
+	Gaps     []Gap     `json:"gaps,omitempty"`
+	// Reversed lists the programmes whose stop time is before their start time.
+	Reversed []int `json:"reversed,omitempty"`
+	// TooLong lists the programmes running longer than AnalyzeOptions.MaxLength.
+	TooLong []int `json:"tooLong,omitempty"`
+}
+
+// Overlap is a pair of programmes on the same channel that are on air at the
+// same time. First starts no later than Second.
+type Overlap struct {
+	First    int           `json:"first"`
+	Second   int           `json:"second"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Gap is a period in which nothing is on air on a channel between two
+// programmes.
+type Gap struct {
+	// After is the programme on air until the gap starts.
+	After int `json:"after"`
+	// Before is the programme starting when the gap ends.
+	Before   int           `json:"before"`
+	Start    time.Time     `json:"start"`
+	Stop     time.Time     `json:"stop"`
+	Duration time.Duration `json:"duration"`
+}
+
+// OK reports whether no problems were found.
+func (r *ScheduleReport) OK() bool {
+	return len(r.Channels) == 0
+}
+
+// Analyze checks each channel's programmes for overlaps, gaps of at least
+// opts.MinGap, stop times before start times and programmes longer than
+// opts.MaxLength. Programmes of the same clump, which share a time slot by
+// design, are not reported as overlapping. Programmes without a stop time are
+// taken to stop when the next programme on the same channel starts.
+func (tv *TV) Analyze(opts AnalyzeOptions) *ScheduleReport {
+	report := &ScheduleReport{}
+
+	starts := newStartIndex(tv.Programmes)
+
+	for _, channel := range tv.scheduleChannels() {
+		r := ChannelReport{Channel: channel.id}
+
+		order := channel.programmes
+
+		for _, i := range order {
+			p := &tv.Programmes[i]
+
+			if p.Stop != nil && p.Stop.Before(p.Start.Time) {
+				r.Reversed = append(r.Reversed, i)
+			}
+
+			if opts.MaxLength > 0 && starts.stop(p).Sub(p.Start.Time) > opts.MaxLength {
+				r.TooLong = append(r.TooLong, i)
+			}
+		}
+
+		for a, i := range order {
+			for _, j := range order[a+1:] {
+				first, second := &tv.Programmes[i], &tv.Programmes[j]
+				if !second.Start.Before(end(first)) {
+					break
+				}
+
+				if overlaps(first, second) && !sameClump(first, second) {
+					stop := end(first)
+					if end(second).Before(stop) {
+						stop = end(second)
+					}
+
+					r.Overlaps = append(r.Overlaps, Overlap{First: i, Second: j, Duration: stop.Sub(second.Start.Time)})
+				}
+			}
+		}
+
+		for _, gap := range channelGaps(tv.Programmes, channel.programmes, starts) {
+			if gap.Duration >= opts.MinGap {
+				r.Gaps = append(r.Gaps, gap)
+			}
+		}
+
+		if len(r.Overlaps) > 0 || len(r.Gaps) > 0 || len(r.Reversed) > 0 || len(r.TooLong) > 0 {
+			report.Channels = append(report.Channels, r)
+		}
+	}
+
+	return report
+}
@@ -0,0 +1,65 @@
+package xmltv
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAnalyze(t *testing.T) {
+	t.Parallel()
+
+	tv := TV{
+		Channels: []Channel{{ID: "one"}, {ID: "two"}, {ID: "three"}},
+		Programmes: []Programme{
+			newProgramme(t, "one", "202203311800", "202203311905", "News"),
+			newProgramme(t, "one", "202203311900", "202203312000", "Film"),
+			newProgramme(t, "one", "202203312030", "202203312100", "Late"),
+			newProgramme(t, "one", "202203312105", "202203312110", "Short gap"),
+			newProgramme(t, "two", "202203311800", "202203311900", "Clump A"),
+			newProgramme(t, "two", "202203311800", "202203311900", "Clump B"),
+			newProgramme(t, "two", "202203311900", "202203311800", "Reversed"),
+			newProgramme(t, "three", "202203310000", "202204010000", "Marathon"),
+		},
+	}
+	tv.Programmes[4].ClumpIndex = makePointer("0/2")
+	tv.Programmes[5].ClumpIndex = makePointer("1/2")
+
+	report := tv.Analyze(AnalyzeOptions{MinGap: 10 * time.Minute, MaxLength: 12 * time.Hour})
+
+	want := &ScheduleReport{
+		Channels: []ChannelReport{
+			{
+				Channel:  "one",
+				Overlaps: []Overlap{{First: 0, Second: 1, Duration: 5 * time.Minute}},
+				Gaps: []Gap{{
+					After:    1,
+					Before:   2,
+					Start:    parseTime(t, "200601021504", "202203312000"),
+					Stop:     parseTime(t, "200601021504", "202203312030"),
+					Duration: 30 * time.Minute,
+				}},
+			},
+			{Channel: "two", Reversed: []int{6}},
+			{Channel: "three", TooLong: []int{7}},
+		},
+	}
+
+	if diff := cmp.Diff(want, report); diff != "" {
+		t.Fatal(diff)
+	}
+
+	if report.OK() {
+		t.Fatal("OK reported no problems")
+	}
+
+	if _, err := json.Marshal(report); err != nil {
+		t.Fatal(err)
+	}
+
+	if !(&TV{Programmes: tv.Programmes[2:3]}).Analyze(AnalyzeOptions{}).OK() {
+		t.Fatal("single programme reported as a problem")
+	}
+}
@@ -0,0 +1,266 @@
+package xmltv
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+)
+
+// LoadOptions configures LoadFS and LoadArchive.
+type LoadOptions struct {
+	// Match reports whether the member with the given slash-separated name is an
+	// XMLTV document. If nil, members named *.xml or *.xmltv, optionally followed
+	// by .gz, .bz2 or .zz, are decoded.
+	Match func(name string) bool
+	// Mode is the decode mode of every member. In lenient mode, a member with
+	// skipped elements is still merged.
+	Mode  DecodeMode
+	Merge MergeOptions
+}
+
+// MemberError is an error reading one member of an archive or file system.
+type MemberError struct {
+	Name string
+	Err  error
+}
+
+func (e *MemberError) Error() string {
+	return fmt.Sprintf("xmltv: %s: %v", e.Name, e.Err)
+}
+
+func (e *MemberError) Unwrap() error {
+	return e.Err
+}
+
+// MemberErrors is the list of every member of an archive or file system that
+// could not be read in full.
+type MemberErrors []*MemberError
+
+func (e MemberErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "xmltv: %d members with errors", len(e))
+
+	for _, err := range e {
+		fmt.Fprintf(&b, "\n\t%s: %v", err.Name, err.Err)
+	}
+
+	return b.String()
+}
+
+func (e MemberErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+
+	return errs
+}
+
+// LoadFS decodes every XMLTV document in fsys and merges them with Merge, in the
+// lexical order of their names. A member that cannot be read does not stop the
+// others from being loaded: the merged document is returned together with
+// MemberErrors reporting each failed member.
+func LoadFS(fsys fs.FS, opts LoadOptions) (*TV, error) {
+	l := newLoader(opts)
+
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			l.fail(name, err)
+
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+
+		if !d.Type().IsRegular() || !l.opts.Match(name) {
+			return nil
+		}
+
+		f, err := fsys.Open(name)
+		if err != nil {
+			l.fail(name, err)
+
+			return nil
+		}
+		defer f.Close()
+
+		l.load(name, f)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return l.result()
+}
+
+// LoadArchive is like LoadFS but loads the members of the named zip or tar
+// archive. A tar archive may be gzip, zlib or bzip2 compressed. If a tar archive
+// is damaged, the members before the damage are still merged and the MemberErrors
+// include one named after the archive.
+func LoadArchive(name string, opts LoadOptions) (*TV, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	var magic [4]byte
+	if _, err := f.ReadAt(magic[:], 0); err == nil && string(magic[:]) == "PK\x03\x04" {
+		zr, err := zip.NewReader(f, info.Size())
+		if err != nil {
+			return nil, fmt.Errorf("xmltv: %s: %w", name, err)
+		}
+
+		return LoadFS(zr, opts)
+	}
+
+	r, err := NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("xmltv: %s: %w", name, err)
+	}
+
+	// A tar header holds the "ustar" magic at offset 257.
+	br := bufio.NewReader(r)
+	if header, _ := br.Peek(262); len(header) < 262 || string(header[257:262]) != "ustar" {
+		return nil, fmt.Errorf("xmltv: %s: not a zip or tar archive", name)
+	}
+
+	l := newLoader(opts)
+	tr := tar.NewReader(br)
+
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			// Keep the members read before the damage.
+			l.fail(name, err)
+
+			break
+		}
+
+		member := strings.TrimPrefix(path.Clean(h.Name), "/")
+		if h.Typeflag != tar.TypeReg || !l.opts.Match(member) {
+			continue
+		}
+
+		l.load(member, tr)
+	}
+
+	return l.result()
+}
+
+// loader collects the documents decoded from the members of an archive.
+type loader struct {
+	opts LoadOptions
+	docs []loadedMember
+	errs MemberErrors
+}
+
+// loadedMember is a document decoded from the named member.
+type loadedMember struct {
+	name string
+	tv   *TV
+}
+
+func newLoader(opts LoadOptions) *loader {
+	if opts.Match == nil {
+		opts.Match = isXMLTVName
+	}
+
+	return &loader{opts: opts}
+}
+
+func (l *loader) fail(name string, err error) {
+	l.errs = append(l.errs, &MemberError{Name: name, Err: err})
+}
+
+// load decodes the member with the given name from r.
+func (l *loader) load(name string, r io.Reader) {
+	r, err := NewReader(r)
+	if err != nil {
+		l.fail(name, err)
+
+		return
+	}
+
+	d := NewDecoder(r)
+	d.SetMode(l.opts.Mode)
+
+	var tv TV
+
+	err = d.Decode(&tv)
+	if err != nil {
+		l.fail(name, err)
+
+		var skipped DecodeErrors
+		if !errors.As(err, &skipped) {
+			return
+		}
+	}
+
+	l.docs = append(l.docs, loadedMember{name: name, tv: &tv})
+}
+
+// result merges the loaded documents in the order of their names.
+func (l *loader) result() (*TV, error) {
+	slices.SortStableFunc(l.docs, func(a, b loadedMember) int {
+		return strings.Compare(a.name, b.name)
+	})
+	slices.SortStableFunc(l.errs, func(a, b *MemberError) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	docs := make([]*TV, len(l.docs))
+	for i, m := range l.docs {
+		docs[i] = m.tv
+	}
+
+	tv, _ := Merge(l.opts.Merge, docs...)
+
+	if len(l.errs) > 0 {
+		return tv, l.errs
+	}
+
+	return tv, nil
+}
+
+// isXMLTVName reports whether name looks like that of an XMLTV document,
+// possibly compressed.
+func isXMLTVName(name string) bool {
+	name = strings.ToLower(name)
+
+	for _, ext := range []string{".gz", ".bz2", ".zz"} {
+		if trimmed, ok := strings.CutSuffix(name, ext); ok {
+			name = trimmed
+
+			break
+		}
+	}
+
+	return path.Ext(name) == ".xml" || path.Ext(name) == ".xmltv"
+}
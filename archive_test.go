@@ -0,0 +1,227 @@
+package xmltv
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type archiveMember struct {
+	name string
+	data []byte
+}
+
+// archiveMembers returns the members of a test archive: a document per day,
+// named so that their lexical order differs from the order they are added in,
+// a malformed document and a file that is not a guide.
+func archiveMembers(t *testing.T) []archiveMember {
+	t.Helper()
+
+	doc := func(title, start string) []byte {
+		tv := &TV{
+			Channels:   []Channel{{ID: "one", DisplayNames: []DisplayName{{Text: "One"}}}},
+			Programmes: []Programme{newProgramme(t, "one", start, "", title)},
+		}
+
+		data, err := xml.Marshal(tv)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return data
+	}
+
+	var gzipped bytes.Buffer
+
+	gz := gzip.NewWriter(&gzipped)
+	if _, err := gz.Write(doc("Tuesday", "202203291800")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return []archiveMember{
+		{name: "days/2022-03-30.xml", data: doc("Wednesday", "202203301800")},
+		{name: "days/2022-03-29.xml.gz", data: gzipped.Bytes()},
+		{name: "days/broken.xml", data: []byte("<tv><programme")},
+		{name: "README.txt", data: []byte("not a guide")},
+	}
+}
+
+func checkLoaded(t *testing.T, tv *TV, err error) {
+	t.Helper()
+
+	var members MemberErrors
+	if !errors.As(err, &members) {
+		t.Fatalf("got %v, want MemberErrors", err)
+	}
+
+	if len(members) != 1 || members[0].Name != "days/broken.xml" {
+		t.Errorf("got %v, want an error for days/broken.xml", err)
+	}
+
+	if diff := cmp.Diff([]string{"Tuesday", "Wednesday"}, programmeTitles(tv.Programmes)); diff != "" {
+		t.Error(diff)
+	}
+
+	if len(tv.Channels) != 1 {
+		t.Errorf("got %d channels, want 1", len(tv.Channels))
+	}
+}
+
+func TestLoadFS(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{}
+	for _, m := range archiveMembers(t) {
+		fsys[m.name] = &fstest.MapFile{Data: m.data}
+	}
+
+	tv, err := LoadFS(fsys, LoadOptions{})
+	checkLoaded(t, tv, err)
+
+	tv, err = LoadFS(fsys, LoadOptions{Match: func(name string) bool { return name == "days/2022-03-30.xml" }})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]string{"Wednesday"}, programmeTitles(tv.Programmes)); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestLoadArchive(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	var zipped bytes.Buffer
+
+	zw := zip.NewWriter(&zipped)
+
+	for _, m := range archiveMembers(t) {
+		w, err := zw.Create(m.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := w.Write(m.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var tarred bytes.Buffer
+
+	gz := gzip.NewWriter(&tarred)
+	tw := tar.NewWriter(gz)
+
+	for _, m := range archiveMembers(t) {
+		if err := tw.WriteHeader(&tar.Header{Name: m.name, Mode: 0o644, Size: int64(len(m.data))}); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := tw.Write(m.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "guide.zip", data: zipped.Bytes()},
+		{name: "guide.tar.gz", data: tarred.Bytes()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			name := filepath.Join(dir, tt.name)
+			if err := os.WriteFile(name, tt.data, 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			tv, err := LoadArchive(name, LoadOptions{})
+			checkLoaded(t, tv, err)
+		})
+	}
+
+	name := filepath.Join(dir, "guide.xml")
+	if err := os.WriteFile(name, []byte("<tv></tv>"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadArchive(name, LoadOptions{}); err == nil {
+		t.Error("got nil error for a file that is not an archive")
+	}
+}
+
+func TestLoadArchiveTruncated(t *testing.T) {
+	t.Parallel()
+
+	var tarred bytes.Buffer
+
+	tw := tar.NewWriter(&tarred)
+
+	members := archiveMembers(t)
+	for _, m := range members {
+		if err := tw.WriteHeader(&tar.Header{Name: m.name, Mode: 0o644, Size: int64(len(m.data))}); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := tw.Write(m.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Cut the archive in the middle of the second member's header.
+	size := 512 + (len(members[0].data)+511)/512*512 + 100
+
+	name := filepath.Join(t.TempDir(), "guide.tar")
+	if err := os.WriteFile(name, tarred.Bytes()[:size], 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tv, err := LoadArchive(name, LoadOptions{})
+
+	var errs MemberErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("got %v, want MemberErrors", err)
+	}
+
+	if len(errs) != 1 || errs[0].Name != name {
+		t.Errorf("got %v, want an error for %s", err, name)
+	}
+
+	if diff := cmp.Diff([]string{"Wednesday"}, programmeTitles(tv.Programmes)); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,130 @@
+package xmltv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// charset is a single-byte character set supported by CharsetReader and
+// Encoder.SetCharset.
+type charset struct {
+	// name is the name written in the XML declaration.
+	name    string
+	charmap *charmap.Charmap
+}
+
+// charsets maps the lower-case names and aliases of the supported character sets
+// to them.
+var charsets = func() map[string]*charset {
+	m := make(map[string]*charset)
+
+	for _, cs := range []struct {
+		charset
+		aliases []string
+	}{
+		{charset{"ISO-8859-1", charmap.ISO8859_1}, []string{"iso8859-1", "iso_8859-1", "latin1", "latin-1", "l1"}},
+		{charset{"ISO-8859-15", charmap.ISO8859_15}, []string{"iso8859-15", "iso_8859-15", "latin9", "latin-9", "l9"}},
+		{charset{"windows-1250", charmap.Windows1250}, []string{"cp1250", "x-cp1250"}},
+		{charset{"windows-1251", charmap.Windows1251}, []string{"cp1251", "x-cp1251"}},
+		{charset{"windows-1252", charmap.Windows1252}, []string{"cp1252", "x-cp1252"}},
+	} {
+		m[strings.ToLower(cs.name)] = &cs.charset
+		for _, alias := range cs.aliases {
+			m[alias] = &cs.charset
+		}
+	}
+
+	return m
+}()
+
+// lookupCharset returns the character set with the given name or alias.
+func lookupCharset(name string) (*charset, error) {
+	cs, ok := charsets[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, fmt.Errorf("xmltv: unsupported charset %q", name)
+	}
+
+	return cs, nil
+}
+
+// CharsetReader returns a reader of input, encoded in the named character set,
+// converted to UTF-8. It supports ISO-8859-1 (Latin-1), ISO-8859-15 (Latin-9)
+// and Windows-1250, 1251 and 1252, under their common aliases. Decoder uses it
+// for documents declaring one of these encodings; it can also be set as the
+// CharsetReader of an xml.Decoder.
+func CharsetReader(name string, input io.Reader) (io.Reader, error) {
+	cs, err := lookupCharset(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return cs.charmap.NewDecoder().Reader(input), nil
+}
+
+// SetCharset sets the encoder to write its output in the named character set,
+// one of those supported by CharsetReader, and to declare it in the XML
+// declaration. Characters the set cannot represent are written as character
+// references. It must be called before anything is written.
+func (enc *Encoder) SetCharset(name string) error {
+	if enc.state != encoderStateInitial {
+		return errors.New("xmltv: charset set after writing")
+	}
+
+	cs, err := lookupCharset(name)
+	if err != nil {
+		return err
+	}
+
+	enc.charset = cs
+	enc.setWriter()
+
+	return nil
+}
+
+// charsetWriter converts the UTF-8 written to it to a single-byte character set.
+type charsetWriter struct {
+	w       io.Writer
+	charmap *charmap.Charmap
+	// partial holds the start of a rune split across writes.
+	partial []byte
+	buf     []byte
+}
+
+func (cw *charsetWriter) Write(p []byte) (int, error) {
+	n := len(p)
+
+	if len(cw.partial) > 0 {
+		p = append(cw.partial, p...)
+		cw.partial = nil
+	}
+
+	cw.buf = cw.buf[:0]
+
+	for len(p) > 0 {
+		r, size := utf8.DecodeRune(p)
+		if r == utf8.RuneError && size <= 1 && !utf8.FullRune(p) {
+			cw.partial = append([]byte(nil), p...)
+
+			break
+		}
+
+		if b, ok := cw.charmap.EncodeRune(r); ok {
+			cw.buf = append(cw.buf, b)
+		} else {
+			cw.buf = fmt.Appendf(cw.buf, "&#%d;", r)
+		}
+
+		p = p[size:]
+	}
+
+	if _, err := cw.w.Write(cw.buf); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
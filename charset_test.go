@@ -0,0 +1,122 @@
+package xmltv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDecoderCharsets(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		charset string
+		title   string
+		want    string
+	}{
+		{charset: "ISO-8859-1", title: "Caf\xe9", want: "Café"},
+		{charset: "latin1", title: "Caf\xe9", want: "Café"},
+		{charset: "ISO-8859-15", title: "\xa4 5", want: "€ 5"},
+		{charset: "windows-1250", title: "\x8aum", want: "Šum"},
+		{charset: "windows-1251", title: "\xcd\xee\xe2\xee\xf1\xf2\xe8", want: "Новости"},
+		{charset: "Windows-1252", title: "\x80 \x93Live\x94", want: "€ “Live”"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.charset, func(t *testing.T) {
+			t.Parallel()
+
+			doc := `<?xml version="1.0" encoding="` + tt.charset + `"?>` +
+				`<tv><programme start="20220331180000 +0000" channel="one"><title>` + tt.title + `</title></programme></tv>`
+
+			var tv TV
+			if err := NewDecoder(strings.NewReader(doc)).Decode(&tv); err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tt.want, tv.Programmes[0].Titles[0].Text); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+
+	doc := `<?xml version="1.0" encoding="EBCDIC"?><tv></tv>`
+	if err := NewDecoder(strings.NewReader(doc)).Decode(&TV{}); err == nil {
+		t.Error("got nil error for an unsupported charset")
+	}
+}
+
+func TestEncoderSetCharset(t *testing.T) {
+	t.Parallel()
+
+	tv := &TV{
+		Programmes: []Programme{{
+			Channel: "one",
+			Start:   Time{Time: parseTime(t, "20060102150405 -0700", "20220331180000 +0000")},
+			Titles:  []Title{{Text: "Café № 5"}},
+		}},
+	}
+
+	var buf bytes.Buffer
+
+	enc := NewEncoder(&buf)
+	if err := enc.SetCharset("latin1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := enc.WriteHeader(tv); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := enc.WriteProgramme(tv.Programmes[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `<?xml version="1.0" encoding="ISO-8859-1"?>` + "\n" +
+		`<tv><programme start="20220331180000 +0000" channel="one"><title>Caf` + "\xe9" + ` &#8470; 5</title></programme></tv>` + "\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Error(diff)
+	}
+
+	var got TV
+	if err := NewDecoder(&buf).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff("Café № 5", got.Programmes[0].Titles[0].Text); diff != "" {
+		t.Error(diff)
+	}
+
+	if err := NewEncoder(&buf).SetCharset("EBCDIC"); err == nil {
+		t.Error("got nil error for an unsupported charset")
+	}
+}
+
+func TestCharsetWriterSplitRunes(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	cs, err := lookupCharset("windows-1252")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := &charsetWriter{w: &buf, charmap: cs.charmap}
+
+	for _, b := range []byte("é€") {
+		if _, err := w.Write([]byte{b}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if diff := cmp.Diff("\xe9\x80", buf.String()); diff != "" {
+		t.Error(diff)
+	}
+}
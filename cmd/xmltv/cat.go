@@ -0,0 +1,26 @@
+package main
+
+// runCat concatenates listings like tv_cat: every programme of every file is
+// kept and channels are unioned by ID. With --merge, programmes that overlap
+// one from a file given earlier are dropped instead.
+func runCat(env *env, args []string) error {
+	fs := newFlagSet(env, "cat", "[file ...]")
+	output := fs.String("output", "", "write to `file` rather than standard output")
+	merge := fs.Bool("merge", false, "drop programmes overlapping one from an earlier file")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	read := readConcatenated
+	if *merge {
+		read = readMerged
+	}
+
+	tv, err := read(env, fs.Args())
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(env, *output, tv)
+}
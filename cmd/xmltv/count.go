@@ -0,0 +1,28 @@
+package main
+
+import "fmt"
+
+// runCount prints the number of programmes, or of channels with --channels, like
+// tv_count.
+func runCount(env *env, args []string) error {
+	fs := newFlagSet(env, "count", "[file ...]")
+	channels := fs.Bool("channels", false, "count channels rather than programmes")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	tv, err := readConcatenated(env, fs.Args())
+	if err != nil {
+		return err
+	}
+
+	n := len(tv.Programmes)
+	if *channels {
+		n = len(tv.Channels)
+	}
+
+	_, err = fmt.Fprintln(env.stdout, n)
+
+	return err
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+
+	"github.com/sherif-fanous/xmltv"
+)
+
+// runGrep selects programmes like tv_grep. Every test given must match; --not
+// selects the programmes that do not.
+func runGrep(env *env, args []string) error {
+	fs := newFlagSet(env, "grep", "[file ...]")
+	output := fs.String("output", "", "write to `file` rather than standard output")
+	ignoreCase := fs.Bool("ignore-case", false, "match regular expressions case-insensitively")
+	fs.BoolVar(ignoreCase, "i", false, "shorthand for --ignore-case")
+	not := fs.Bool("not", false, "select the programmes that do not match")
+
+	var channelIDs stringList
+	fs.Var(&channelIDs, "channel-id", "select programmes on the channel with this `id` (may be repeated)")
+
+	channelName := fs.String("channel-name", "", "select programmes on channels with a display name matching `regexp`")
+	title := fs.String("title", "", "select programmes with a title matching `regexp`")
+	subTitle := fs.String("sub-title", "", "select programmes with a sub-title matching `regexp`")
+	desc := fs.String("desc", "", "select programmes with a description matching `regexp`")
+	category := fs.String("category", "", "select programmes with a category matching `regexp`")
+	onAfter := fs.String("on-after", "", "select programmes still on air at or after `time`")
+	onBefore := fs.String("on-before", "", "select programmes finished at or before `time`")
+	query := fs.String("query", "", "select programmes matching the query `expression`, as accepted by xmltv.Compile")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	tv, err := readConcatenated(env, fs.Args())
+	if err != nil {
+		return err
+	}
+
+	compile := func(expr string) (*regexp.Regexp, error) {
+		if *ignoreCase {
+			expr = "(?i)" + expr
+		}
+
+		return regexp.Compile(expr)
+	}
+
+	var tests []func(*xmltv.Programme) bool
+
+	if *channelName != "" {
+		re, err := compile(*channelName)
+		if err != nil {
+			return err
+		}
+
+		for _, c := range tv.Channels {
+			if slices.ContainsFunc(c.DisplayNames, func(d xmltv.DisplayName) bool { return re.MatchString(d.Text) }) {
+				channelIDs = append(channelIDs, c.ID)
+			}
+		}
+
+		if len(channelIDs) == 0 {
+			return errors.New("no channel matches --channel-name")
+		}
+	}
+
+	if len(channelIDs) > 0 {
+		tests = append(tests, func(p *xmltv.Programme) bool { return slices.Contains(channelIDs, p.Channel) })
+
+		tv.Channels = slices.DeleteFunc(tv.Channels, func(c xmltv.Channel) bool {
+			return !slices.Contains(channelIDs, c.ID)
+		})
+	}
+
+	for _, field := range []struct {
+		expr  string
+		texts func(*xmltv.Programme) []string
+	}{
+		{*title, func(p *xmltv.Programme) []string {
+			return texts(p.Titles, func(t xmltv.Title) string { return t.Text })
+		}},
+		{*subTitle, func(p *xmltv.Programme) []string {
+			return texts(p.SubTitles, func(t xmltv.SubTitle) string { return t.Text })
+		}},
+		{*desc, func(p *xmltv.Programme) []string {
+			return texts(p.Descriptions, func(d xmltv.Description) string { return d.Text })
+		}},
+		{*category, func(p *xmltv.Programme) []string {
+			return texts(p.Categories, func(c xmltv.Category) string { return c.Text })
+		}},
+	} {
+		if field.expr == "" {
+			continue
+		}
+
+		re, err := compile(field.expr)
+		if err != nil {
+			return err
+		}
+
+		tests = append(tests, func(p *xmltv.Programme) bool {
+			return slices.ContainsFunc(field.texts(p), re.MatchString)
+		})
+	}
+
+	if *onAfter != "" {
+		t, err := parseTime(*onAfter)
+		if err != nil {
+			return err
+		}
+
+		tests = append(tests, func(p *xmltv.Programme) bool {
+			if p.Stop == nil {
+				return !p.Start.Before(t.Time)
+			}
+
+			return p.Stop.After(t.Time)
+		})
+	}
+
+	if *onBefore != "" {
+		t, err := parseTime(*onBefore)
+		if err != nil {
+			return err
+		}
+
+		tests = append(tests, func(p *xmltv.Programme) bool {
+			if p.Stop == nil {
+				return p.Start.Before(t.Time)
+			}
+
+			return !p.Stop.After(t.Time)
+		})
+	}
+
+	if *query != "" {
+		m, err := xmltv.Compile(*query)
+		if err != nil {
+			return err
+		}
+
+		tests = append(tests, m.Match)
+	}
+
+	tv.Programmes = slices.DeleteFunc(tv.Programmes, func(p xmltv.Programme) bool {
+		match := true
+		for _, test := range tests {
+			if !test(&p) {
+				match = false
+
+				break
+			}
+		}
+
+		return match == *not
+	})
+
+	return writeOutput(env, *output, tv)
+}
+
+// texts returns the text of each element of values.
+func texts[T any](values []T, text func(T) string) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = text(v)
+	}
+
+	return out
+}
@@ -0,0 +1,319 @@
+// Command xmltv manipulates XMLTV listings. Its commands cover the everyday
+// tv_* tools distributed with XMLTV, with similar flags.
+//
+// Usage:
+//
+//	xmltv <command> [flags] [file ...]
+//
+// The commands are:
+//
+//	validate  check listings against the XMLTV DTD, like tv_validate_file
+//	cat       concatenate listings, like tv_cat
+//	sort      sort programmes and add missing stop times, like tv_sort
+//	grep      select programmes matching tests, like tv_grep
+//	split     split listings into files by channel and day, like tv_split
+//	count     count programmes, like tv_count
+//	stats     report per-channel schedule statistics and problems
+//
+// Listings are read from the named files, or from standard input when none are
+// given or the name is "-", and may be gzip, zlib or bzip2 compressed. The XMLTV
+// files in a zip or tar archive are read as one merged listing. Output is
+// written to standard output unless the --output flag names a file, which is
+// gzip compressed if its name ends in ".gz".
+package main
+
+import (
+	"compress/gzip"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/sherif-fanous/xmltv"
+)
+
+// command is a subcommand of the xmltv tool.
+type command struct {
+	summary string
+	run     func(env *env, args []string) error
+}
+
+var commands = map[string]command{
+	"validate": {summary: "check listings against the XMLTV DTD", run: runValidate},
+	"cat":      {summary: "concatenate listings", run: runCat},
+	"sort":     {summary: "sort programmes and add missing stop times", run: runSort},
+	"grep":     {summary: "select programmes matching tests", run: runGrep},
+	"split":    {summary: "split listings into files by channel and day", run: runSplit},
+	"count":    {summary: "count programmes", run: runCount},
+	"stats":    {summary: "report per-channel schedule statistics and problems", run: runStats},
+}
+
+// errFailed signals a command that ran to completion but found problems, such as
+// invalid listings. Its details have already been reported.
+var errFailed = errors.New("failed")
+
+// env holds the standard streams of a command.
+type env struct {
+	stdin          io.Reader
+	stdout, stderr io.Writer
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], &env{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}))
+}
+
+// run runs the command named by args[0] and returns the process exit code.
+func run(args []string, env *env) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(env.stderr)
+
+		return 2
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(env.stderr, "xmltv: unknown command %q\n", args[0])
+		usage(env.stderr)
+
+		return 2
+	}
+
+	if err := cmd.run(env, args[1:]); err != nil {
+		switch {
+		case errors.Is(err, flag.ErrHelp):
+			return 2
+		case errors.Is(err, errFailed):
+			return 1
+		default:
+			fmt.Fprintf(env.stderr, "xmltv %s: %v\n", args[0], err)
+
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: xmltv <command> [flags] [file ...]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-9s %s\n", name, commands[name].summary)
+	}
+}
+
+// newFlagSet returns a flag set for the named command that reports errors to
+// env's standard error.
+func newFlagSet(env *env, name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(env.stderr, "usage: xmltv %s [flags] %s\n", name, args)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+// stringList is a flag that may be given several times.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+
+	return nil
+}
+
+// readFile decodes the listings in the named file, or standard input for "-",
+// decompressing gzip, zlib or bzip2 input.
+func readFile(env *env, name string) (*xmltv.TV, error) {
+	return readFileMode(env, name, xmltv.DecodeModeDefault)
+}
+
+// readFileMode is like readFile but decodes in the given mode.
+func readFileMode(env *env, name string, mode xmltv.DecodeMode) (*xmltv.TV, error) {
+	if isArchive(name) {
+		tv, err := xmltv.LoadArchive(name, xmltv.LoadOptions{Mode: mode})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		return tv, nil
+	}
+
+	r := env.stdin
+
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		r = f
+	}
+
+	r, err := xmltv.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	d := xmltv.NewDecoder(r)
+	d.SetMode(mode)
+
+	var tv xmltv.TV
+	if err := d.Decode(&tv); err != nil {
+		if name == "-" {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return &tv, nil
+}
+
+// isArchive reports whether name is that of a zip or tar archive, whose XMLTV
+// members are merged when read.
+func isArchive(name string) bool {
+	name = strings.ToLower(name)
+
+	for _, ext := range []string{".zip", ".tar", ".tgz", ".tar.gz", ".tar.bz2"} {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// readFiles decodes the listings in each of the named files, or standard input
+// when there are none.
+func readFiles(env *env, names []string) ([]*xmltv.TV, error) {
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+
+	docs := make([]*xmltv.TV, len(names))
+
+	for i, name := range names {
+		tv, err := readFile(env, name)
+		if err != nil {
+			return nil, err
+		}
+
+		docs[i] = tv
+	}
+
+	return docs, nil
+}
+
+// readConcatenated decodes the named files like readFiles and concatenates
+// them into a single document, like tv_cat: the programmes of every file are
+// kept, channels are unioned by ID with the first file's definition winning, and
+// the root attributes are taken from the first file.
+func readConcatenated(env *env, names []string) (*xmltv.TV, error) {
+	docs, err := readFiles(env, names)
+	if err != nil {
+		return nil, err
+	}
+
+	tv := docs[0]
+
+	seen := make(map[string]bool)
+	for _, c := range tv.Channels {
+		seen[c.ID] = true
+	}
+
+	for _, doc := range docs[1:] {
+		for _, c := range doc.Channels {
+			if !seen[c.ID] {
+				seen[c.ID] = true
+				tv.Channels = append(tv.Channels, c)
+			}
+		}
+
+		tv.Programmes = append(tv.Programmes, doc.Programmes...)
+		tv.Extensions = append(tv.Extensions, doc.Extensions...)
+	}
+
+	return tv, nil
+}
+
+// readMerged decodes the named files like readFiles and merges them with
+// xmltv.Merge, so that where programmes from different files overlap, the file
+// given first wins.
+func readMerged(env *env, names []string) (*xmltv.TV, error) {
+	docs, err := readFiles(env, names)
+	if err != nil {
+		return nil, err
+	}
+
+	tv, _ := xmltv.Merge(xmltv.MergeOptions{}, docs...)
+
+	return tv, nil
+}
+
+// writeOutput writes tv to the named file, or standard output when name is
+// empty.
+func writeOutput(env *env, name string, tv *xmltv.TV) error {
+	if name == "" {
+		return encode(env.stdout, tv, false)
+	}
+
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+
+	if err := encode(f, tv, strings.HasSuffix(name, ".gz")); err != nil {
+		f.Close()
+
+		return err
+	}
+
+	return f.Close()
+}
+
+// encode writes tv as an indented document with a DOCTYPE, as the tv_* tools do,
+// gzip compressed if compress is set.
+func encode(w io.Writer, tv *xmltv.TV, compress bool) error {
+	enc := xmltv.NewEncoder(w)
+	enc.Indent("", "  ")
+	enc.SetDocType(true)
+
+	if compress {
+		if err := enc.SetGzipLevel(gzip.DefaultCompression); err != nil {
+			return err
+		}
+	}
+
+	return enc.Encode(tv)
+}
+
+// parseTime parses an XMLTV date/time such as "20220331180000 +0000".
+func parseTime(value string) (xmltv.Time, error) {
+	var t xmltv.Time
+	if err := t.UnmarshalXMLAttr(xml.Attr{Value: value}); err != nil {
+		return xmltv.Time{}, err
+	}
+
+	return t, nil
+}
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const listings = `<?xml version="1.0" encoding="UTF-8"?>
+<tv>
+  <channel id="one.tv">
+    <display-name>One</display-name>
+  </channel>
+  <channel id="two.tv">
+    <display-name>Two</display-name>
+  </channel>
+  <programme start="20220331200000 +0000" stop="20220331210000 +0000" channel="one.tv">
+    <title>Film</title>
+  </programme>
+  <programme start="20220331180000 +0000" channel="one.tv">
+    <title>News</title>
+    <length units="minutes">60</length>
+  </programme>
+  <programme start="20220331180000 +0000" stop="20220331190000 +0000" channel="two.tv">
+    <title>Sport</title>
+  </programme>
+  <programme start="20220401180000 +0000" stop="20220401190000 +0000" channel="two.tv">
+    <title>Evening News</title>
+  </programme>
+</tv>
+`
+
+func runCommand(t *testing.T, stdin string, args ...string) (code int, stdout, stderr string) {
+	t.Helper()
+
+	var out, errOut bytes.Buffer
+
+	code = run(args, &env{stdin: strings.NewReader(stdin), stdout: &out, stderr: &errOut})
+
+	return code, out.String(), errOut.String()
+}
+
+func TestCount(t *testing.T) {
+	t.Parallel()
+
+	code, stdout, stderr := runCommand(t, listings, "count")
+	if code != 0 {
+		t.Fatalf("count = %d, %q", code, stderr)
+	}
+
+	if diff := cmp.Diff("4\n", stdout); diff != "" {
+		t.Fatal(diff)
+	}
+
+	code, stdout, stderr = runCommand(t, listings, "count", "--channels")
+	if code != 0 {
+		t.Fatalf("count --channels = %d, %q", code, stderr)
+	}
+
+	if diff := cmp.Diff("2\n", stdout); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	code, stdout, stderr := runCommand(t, listings, "validate")
+	if code != 0 {
+		t.Fatalf("validate = %d, %q", code, stderr)
+	}
+
+	if diff := cmp.Diff("-: valid\n", stdout); diff != "" {
+		t.Fatal(diff)
+	}
+
+	invalid := strings.Replace(listings, `channel="two.tv"`, `channel=""`, 1)
+
+	code, stdout, _ = runCommand(t, invalid, "validate")
+	if code != 1 || !strings.Contains(stdout, "programmes[2].channel") {
+		t.Fatalf("validate invalid = %d, %q; want 1 and an error for programmes[2].channel", code, stdout)
+	}
+
+	extended := strings.Replace(listings, `<title>Sport</title>`, `<title>Sport</title><catchup id="1"/>`, 1)
+
+	if code, _, _ = runCommand(t, extended, "validate"); code != 0 {
+		t.Fatalf("validate extended = %d, want 0", code)
+	}
+
+	code, stdout, _ = runCommand(t, extended, "validate", "--strict")
+	if code != 1 || !strings.Contains(stdout, "/tv/programme[3]/catchup[1]") {
+		t.Fatalf("validate --strict extended = %d, %q; want 1 and an error for /tv/programme[3]/catchup[1]", code, stdout)
+	}
+}
+
+func TestGrep(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{args: []string{"--title", "news"}, want: nil},
+		{args: []string{"-i", "--title", "news"}, want: []string{"News", "Evening News"}},
+		{args: []string{"-i", "--not", "--title", "news"}, want: []string{"Film", "Sport"}},
+		{args: []string{"--channel-id", "two.tv"}, want: []string{"Sport", "Evening News"}},
+		{args: []string{"--channel-name", "^One$", "--title", "Film"}, want: []string{"Film"}},
+		{args: []string{"--on-after", "20220331190000 +0000"}, want: []string{"Film", "Evening News"}},
+		{args: []string{"--on-before", "20220331190000 +0000"}, want: []string{"News", "Sport"}},
+		{args: []string{"--query", `title ~ "News" and start >= 18:00 and channel = "two.tv"`}, want: []string{"Evening News"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			t.Parallel()
+
+			code, stdout, stderr := runCommand(t, listings, append([]string{"grep"}, tt.args...)...)
+			if code != 0 {
+				t.Fatalf("grep = %d, %q", code, stderr)
+			}
+
+			var got []string
+
+			for _, title := range []string{"Film", "News", "Sport", "Evening News"} {
+				if strings.Contains(stdout, "<title>"+title+"</title>") {
+					got = append(got, title)
+				}
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestSort(t *testing.T) {
+	t.Parallel()
+
+	code, stdout, stderr := runCommand(t, listings, "sort", "--by-channel")
+	if code != 0 {
+		t.Fatalf("sort = %d, %q", code, stderr)
+	}
+
+	var last int
+	for _, title := range []string{"News", "Film", "Sport", "Evening News"} {
+		i := strings.Index(stdout, "<title>"+title+"</title>")
+		if i < last {
+			t.Fatalf("%q out of order in:\n%s", title, stdout)
+		}
+
+		last = i
+	}
+
+	if !strings.Contains(stdout, `start="20220331180000 +0000" stop="20220331200000 +0000" channel="one.tv"`) {
+		t.Fatalf("stop time of News not filled in:\n%s", stdout)
+	}
+
+	if stderr == "" {
+		t.Fatal("no warning for the length of News disagreeing with its inferred stop time")
+	}
+}
+
+func TestSplit(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	code, _, stderr := runCommand(t, listings, "split", "--output", filepath.Join(dir, "%channel-%Y%m%d.xml"))
+	if code != 0 {
+		t.Fatalf("split = %d, %q", code, stderr)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+
+	want := []string{"one.tv-20220331.xml", "two.tv-20220331.xml", "two.tv-20220401.xml"}
+	if diff := cmp.Diff(want, names); diff != "" {
+		t.Fatal(diff)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "two.tv-20220401.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := string(data); !strings.Contains(s, `<channel id="two.tv">`) || strings.Contains(s, `<channel id="one.tv">`) ||
+		strings.Contains(s, "Sport") {
+		t.Fatalf("two.tv-20220401.xml =\n%s", s)
+	}
+}
+
+func TestStats(t *testing.T) {
+	t.Parallel()
+
+	overlapping := strings.Replace(listings, `start="20220331200000 +0000"`, `start="20220331183000 +0000"`, 1)
+
+	code, stdout, stderr := runCommand(t, overlapping, "stats", "--fail")
+	if code != 1 {
+		t.Fatalf("stats --fail = %d, %q; want 1", code, stderr)
+	}
+
+	if !strings.Contains(stdout, "one.tv") || !strings.Contains(stdout, "OVERLAPS") {
+		t.Fatalf("stats output =\n%s", stdout)
+	}
+
+	code, _, stderr = runCommand(t, listings, "stats", "--fail", "--min-gap", "48h")
+	if code != 0 {
+		t.Fatalf("stats --fail on clean listings = %d, %q; want 0", code, stderr)
+	}
+}
+
+func TestUnknownCommand(t *testing.T) {
+	t.Parallel()
+
+	code, _, _ := runCommand(t, "", "frobnicate")
+	if diff := cmp.Diff(2, code); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestCompressedFiles(t *testing.T) {
+	t.Parallel()
+
+	name := filepath.Join(t.TempDir(), "listings.xml.gz")
+
+	code, _, stderr := runCommand(t, listings, "cat", "--output", name)
+	if code != 0 {
+		t.Fatalf("cat = %d, %q", code, stderr)
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		t.Fatalf("%s is not gzip compressed", name)
+	}
+
+	code, stdout, stderr := runCommand(t, "", "count", name)
+	if code != 0 {
+		t.Fatalf("count = %d, %q", code, stderr)
+	}
+
+	if diff := cmp.Diff("4\n", stdout); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestArchive(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	zw := zip.NewWriter(&buf)
+
+	for _, name := range []string{"a.xml", "b.xml"} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := w.Write([]byte(listings)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	name := filepath.Join(t.TempDir(), "listings.zip")
+	if err := os.WriteFile(name, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	code, stdout, stderr := runCommand(t, "", "count", name)
+	if code != 0 {
+		t.Fatalf("count = %d, %q", code, stderr)
+	}
+
+	if diff := cmp.Diff("4\n", stdout); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestCatConcatenates(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.xml")
+	b := filepath.Join(dir, "b.xml")
+
+	other := strings.ReplaceAll(strings.ReplaceAll(listings, "<title>News</title>", "<title>Headlines</title>"),
+		`<channel id="two.tv">`, `<channel id="three.tv">`)
+
+	if err := os.WriteFile(a, []byte(listings), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(b, []byte(other), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	code, stdout, stderr := runCommand(t, "", "count", a, b)
+	if code != 0 {
+		t.Fatalf("count = %d, %q", code, stderr)
+	}
+
+	if diff := cmp.Diff("8\n", stdout); diff != "" {
+		t.Fatal(diff)
+	}
+
+	code, stdout, stderr = runCommand(t, "", "cat", a, b)
+	if code != 0 {
+		t.Fatalf("cat = %d, %q", code, stderr)
+	}
+
+	if strings.Count(stdout, "<programme ") != 8 || !strings.Contains(stdout, "Headlines") {
+		t.Fatalf("cat lost programmes:\n%s", stdout)
+	}
+
+	if strings.Count(stdout, `<channel id="one.tv">`) != 1 || !strings.Contains(stdout, `<channel id="three.tv">`) {
+		t.Fatalf("cat did not union channels by ID:\n%s", stdout)
+	}
+
+	code, stdout, stderr = runCommand(t, "", "cat", "--merge", a, b)
+	if code != 0 {
+		t.Fatalf("cat --merge = %d, %q", code, stderr)
+	}
+
+	if strings.Count(stdout, "<programme ") != 4 || strings.Contains(stdout, "Headlines") {
+		t.Fatalf("cat --merge kept overlapping programmes:\n%s", stdout)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/sherif-fanous/xmltv"
+)
+
+// runSort sorts programmes and adds missing stop times like tv_sort. Programmes
+// are sorted by start time, or by channel and then start time with --by-channel.
+func runSort(env *env, args []string) error {
+	fs := newFlagSet(env, "sort", "[file ...]")
+	output := fs.String("output", "", "write to `file` rather than standard output")
+	byChannel := fs.Bool("by-channel", false, "sort by channel, then by start time")
+	tolerance := fs.Duration("tolerance", 5*time.Minute, "report programmes whose length disagrees with the added stop time by more than `duration`")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	tv, err := readConcatenated(env, fs.Args())
+	if err != nil {
+		return err
+	}
+
+	for _, m := range tv.FillStops(*tolerance) {
+		p := tv.Programmes[m.Programme]
+		fmt.Fprintf(env.stderr, "xmltv sort: %s at %s: stop time %s disagrees with length %v\n",
+			m.Channel, p.Start.Format(time.DateTime), m.Inferred.Format(time.DateTime), m.Length)
+	}
+
+	if !*byChannel {
+		slices.SortStableFunc(tv.Programmes, func(a, b xmltv.Programme) int {
+			return a.Start.Compare(b.Start.Time)
+		})
+	}
+
+	return writeOutput(env, *output, tv)
+}
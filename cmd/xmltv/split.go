@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/sherif-fanous/xmltv"
+)
+
+// runSplit splits listings into several files like tv_split. The --output
+// template names each file; in it %channel is replaced by the channel ID and
+// %Y, %m and %d by the year, month and day the programme starts.
+func runSplit(env *env, args []string) error {
+	fs := newFlagSet(env, "split", "--output template [file ...]")
+	output := fs.String("output", "", "write to files named by `template`, using %channel, %Y, %m and %d")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *output == "" {
+		fs.Usage()
+
+		return errors.New("--output is required")
+	}
+
+	tv, err := readConcatenated(env, fs.Args())
+	if err != nil {
+		return err
+	}
+
+	channels := make(map[string]xmltv.Channel)
+	for _, c := range tv.Channels {
+		channels[c.ID] = c
+	}
+
+	parts := make(map[string]*xmltv.TV)
+
+	var names []string
+
+	for _, p := range tv.Programmes {
+		name := strings.NewReplacer(
+			"%channel", p.Channel,
+			"%Y", p.Start.Format("2006"),
+			"%m", p.Start.Format("01"),
+			"%d", p.Start.Format("02"),
+		).Replace(*output)
+
+		part, ok := parts[name]
+		if !ok {
+			part = &xmltv.TV{
+				Date:              tv.Date,
+				SourceInfoURL:     tv.SourceInfoURL,
+				SourceInfoName:    tv.SourceInfoName,
+				SourceDataURL:     tv.SourceDataURL,
+				GeneratorInfoName: tv.GeneratorInfoName,
+				GeneratorInfoURL:  tv.GeneratorInfoURL,
+			}
+			parts[name] = part
+			names = append(names, name)
+		}
+
+		if c, ok := channels[p.Channel]; ok && !slices.ContainsFunc(part.Channels, func(pc xmltv.Channel) bool { return pc.ID == c.ID }) {
+			part.Channels = append(part.Channels, c)
+		}
+
+		part.Programmes = append(part.Programmes, p)
+	}
+
+	slices.Sort(names)
+
+	for _, name := range names {
+		if err := writeOutput(env, name, parts[name]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
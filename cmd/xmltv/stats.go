@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sherif-fanous/xmltv"
+)
+
+// runStats prints, for each channel, the number of programmes, the period they
+// cover and the number of schedule problems found by TV.Analyze. With --json the
+// full analysis is printed instead. With --fail it exits with status 1 if any
+// problem is found.
+func runStats(env *env, args []string) error {
+	fs := newFlagSet(env, "stats", "[file ...]")
+	minGap := fs.Duration("min-gap", time.Minute, "report gaps of at least `duration`")
+	maxLength := fs.Duration("max-length", 12*time.Hour, "report programmes running longer than `duration`")
+	asJSON := fs.Bool("json", false, "print the full analysis as JSON")
+	fail := fs.Bool("fail", false, "exit with status 1 if any problem is found")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	tv, err := readConcatenated(env, fs.Args())
+	if err != nil {
+		return err
+	}
+
+	report := tv.Analyze(xmltv.AnalyzeOptions{MinGap: *minGap, MaxLength: *maxLength})
+
+	if *asJSON {
+		enc := json.NewEncoder(env.stdout)
+		enc.SetIndent("", "  ")
+
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else if err := printStats(env, tv, report); err != nil {
+		return err
+	}
+
+	if *fail && !report.OK() {
+		return errFailed
+	}
+
+	return nil
+}
+
+// channelStats summarises the programmes of a channel.
+type channelStats struct {
+	programmes  int
+	first, last time.Time
+}
+
+func printStats(env *env, tv *xmltv.TV, report *xmltv.ScheduleReport) error {
+	var ids []string
+
+	stats := make(map[string]*channelStats)
+	for _, c := range tv.Channels {
+		if _, ok := stats[c.ID]; !ok {
+			ids = append(ids, c.ID)
+			stats[c.ID] = &channelStats{}
+		}
+	}
+
+	for _, p := range tv.Programmes {
+		s, ok := stats[p.Channel]
+		if !ok {
+			ids = append(ids, p.Channel)
+			s = &channelStats{}
+			stats[p.Channel] = s
+		}
+
+		s.programmes++
+
+		if s.first.IsZero() || p.Start.Before(s.first) {
+			s.first = p.Start.Time
+		}
+
+		last := p.Start.Time
+		if p.Stop != nil {
+			last = p.Stop.Time
+		}
+
+		if last.After(s.last) {
+			s.last = last
+		}
+	}
+
+	problems := make(map[string]xmltv.ChannelReport)
+	for _, r := range report.Channels {
+		problems[r.Channel] = r
+	}
+
+	w := tabwriter.NewWriter(env.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHANNEL\tPROGRAMMES\tFIRST\tLAST\tOVERLAPS\tGAPS\tREVERSED\tTOO LONG")
+
+	for _, id := range ids {
+		s, r := stats[id], problems[id]
+
+		first, last := "-", "-"
+		if s.programmes > 0 {
+			first, last = s.first.Format(time.DateTime), s.last.Format(time.DateTime)
+		}
+
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%d\t%d\t%d\t%d\n",
+			id, s.programmes, first, last, len(r.Overlaps), len(r.Gaps), len(r.Reversed), len(r.TooLong))
+	}
+
+	return w.Flush()
+}
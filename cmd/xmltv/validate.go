@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sherif-fanous/xmltv"
+)
+
+// runValidate checks each file against the DTD, reporting every violation. Like
+// tv_validate_file, it exits with status 1 if any file is invalid. With --strict,
+// content outside the DTD, such as unknown elements, is rejected as well.
+func runValidate(env *env, args []string) error {
+	fs := newFlagSet(env, "validate", "[file ...]")
+	quiet := fs.Bool("quiet", false, "only report invalid files")
+	strict := fs.Bool("strict", false, "reject elements, attributes and values outside the DTD")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	names := fs.Args()
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+
+	failed := false
+
+	mode := xmltv.DecodeModeDefault
+	if *strict {
+		mode = xmltv.DecodeModeStrict
+	}
+
+	for _, name := range names {
+		tv, err := readFileMode(env, name, mode)
+		if err == nil {
+			err = tv.Validate()
+		}
+
+		if err == nil {
+			if !*quiet {
+				fmt.Fprintf(env.stdout, "%s: valid\n", name)
+			}
+
+			continue
+		}
+
+		failed = true
+
+		var violations xmltv.ValidationErrors
+		if !errors.As(err, &violations) {
+			fmt.Fprintf(env.stdout, "%s: %v\n", name, err)
+
+			continue
+		}
+
+		for _, v := range violations {
+			fmt.Fprintf(env.stdout, "%s: %s: %s (%s)\n", name, v.Path, v.Message, v.Code)
+		}
+	}
+
+	if failed {
+		return errFailed
+	}
+
+	return nil
+}
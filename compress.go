@@ -0,0 +1,103 @@
+package xmltv
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// compression is a compression format recognised by NewReader.
+type compression int
+
+const (
+	compressionNone compression = iota
+	compressionGzip
+	compressionZlib
+	compressionBzip2
+)
+
+// NewReader returns a reader of the uncompressed content of r. The compression
+// format, gzip, zlib or bzip2, is detected from the first bytes of r; any other
+// input, such as plain XML, is returned as is.
+func NewReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+
+	switch detectCompression(br) {
+	case compressionGzip:
+		return gzip.NewReader(br)
+	case compressionZlib:
+		return zlib.NewReader(br)
+	case compressionBzip2:
+		return bzip2.NewReader(br), nil
+	default:
+		return br, nil
+	}
+}
+
+// detectCompression returns the compression format of the content of r, judged
+// from its magic bytes.
+func detectCompression(r *bufio.Reader) compression {
+	magic, _ := r.Peek(3)
+
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		return compressionGzip
+	case bytes.HasPrefix(magic, []byte("BZh")):
+		return compressionBzip2
+	case len(magic) >= 2 && magic[0]&0x0f == 8 && magic[0]>>4 <= 7 && (uint16(magic[0])<<8|uint16(magic[1]))%31 == 0:
+		// A zlib header: the deflate method and a check value making the first
+		// two bytes a multiple of 31. No XML document starts this way.
+		return compressionZlib
+	default:
+		return compressionNone
+	}
+}
+
+// Open reads the XMLTV document in the named file, decompressing it as
+// NewReader does.
+func Open(name string) (*TV, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r, err := NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("xmltv: %s: %w", name, err)
+	}
+
+	var tv TV
+	if err := NewDecoder(r).Decode(&tv); err != nil {
+		return nil, err
+	}
+
+	return &tv, nil
+}
+
+// SetGzipLevel sets the encoder to compress its output with gzip at level, one
+// of gzip.DefaultCompression, gzip.NoCompression, gzip.HuffmanOnly or an integer
+// from gzip.BestSpeed to gzip.BestCompression. It must be called before anything
+// is written. Close finishes the gzip stream but does not close the underlying
+// writer.
+func (enc *Encoder) SetGzipLevel(level int) error {
+	if enc.state != encoderStateInitial {
+		return errors.New("xmltv: compression set after writing")
+	}
+
+	gz, err := gzip.NewWriterLevel(enc.out, level)
+	if err != nil {
+		return fmt.Errorf("xmltv: %w", err)
+	}
+
+	enc.compressor = gz
+	enc.setWriter()
+
+	return nil
+}
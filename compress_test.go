@@ -0,0 +1,190 @@
+package xmltv
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewReader(t *testing.T) {
+	t.Parallel()
+
+	data, err := os.ReadFile("testdata/unmarshal/epg.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bzipped, err := os.ReadFile("testdata/unmarshal/epg.xml.bz2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compress := func(newWriter func(io.Writer) io.WriteCloser) []byte {
+		var buf bytes.Buffer
+
+		w := newWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name  string
+		input []byte
+	}{
+		{name: "plain", input: data},
+		{name: "gzip", input: compress(func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) })},
+		{name: "zlib", input: compress(func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) })},
+		{name: "bzip2", input: bzipped},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := NewReader(bytes.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(got, data) {
+				t.Error("decompressed content differs from the original")
+			}
+		})
+	}
+}
+
+func TestOpen(t *testing.T) {
+	t.Parallel()
+
+	data, err := os.ReadFile("testdata/unmarshal/epg.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want TV
+	if err := xml.Unmarshal(data, &want); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	name := filepath.Join(t.TempDir(), "epg.xml.gz")
+	if err := os.WriteFile(name, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(want, *got); diff != "" {
+		t.Error(diff)
+	}
+
+	if _, err := Open(filepath.Join(t.TempDir(), "missing.xml")); err == nil {
+		t.Error("got nil error for a missing file")
+	}
+}
+
+func TestEncoderSetGzipLevel(t *testing.T) {
+	t.Parallel()
+
+	tv := &TV{
+		Channels:   []Channel{{ID: "one", DisplayNames: []DisplayName{{Text: "One"}}}},
+		Programmes: []Programme{{Channel: "one", Start: Time{Time: parseTime(t, "20060102150405 -0700", "20220331180000 +0000")}, Titles: []Title{{Text: "News"}}}},
+	}
+
+	encode := func(level *int) []byte {
+		var buf bytes.Buffer
+
+		enc := NewEncoder(&buf)
+		enc.Indent("", "  ")
+
+		if level != nil {
+			if err := enc.SetGzipLevel(*level); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if err := enc.WriteHeader(tv); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := enc.WriteChannel(tv.Channels[0]); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := enc.WriteProgramme(tv.Programmes[0]); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := enc.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		return buf.Bytes()
+	}
+
+	want := encode(nil)
+
+	compressed := encode(makePointer(gzip.BestCompression))
+	if !bytes.HasPrefix(compressed, []byte{0x1f, 0x8b}) {
+		t.Fatal("output is not gzip compressed")
+	}
+
+	r, err := NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(string(want), string(got)); diff != "" {
+		t.Error(diff)
+	}
+
+	if err := NewEncoder(io.Discard).SetGzipLevel(42); err == nil {
+		t.Error("got nil error for an invalid level")
+	}
+
+	enc := NewEncoder(io.Discard)
+	if err := enc.WriteHeader(tv); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := enc.SetGzipLevel(gzip.BestSpeed); err == nil {
+		t.Error("got nil error after writing")
+	}
+}
@@ -0,0 +1,79 @@
+package xmltv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DecodeMode selects how a Decoder treats content that does not conform to the
+// DTD.
+type DecodeMode int
+
+const (
+	// DecodeModeDefault accepts what xml.Unmarshal accepts. Unknown attributes
+	// and elements are kept in Attrs and Extensions, and malformed yes/no values
+	// read as false.
+	DecodeModeDefault DecodeMode = iota
+	// DecodeModeStrict rejects unknown attributes and elements, unexpected text
+	// and values that are malformed or outside the DTD's enumerations, such as a
+	// yes/no value other than yes or no or an image size other than 1, 2 or 3.
+	// The first such problem stops decoding with a *DecodeError.
+	DecodeModeStrict
+	// DecodeModeLenient skips the channels and programmes that cannot be
+	// decoded, such as a programme with an unparseable start time, and carries
+	// on. A *DecodeError is recorded for each, and Decode returns them all as
+	// DecodeErrors once the rest of the document has been read. Malformed XML
+	// still stops decoding.
+	DecodeModeLenient
+)
+
+// DecodeError is an error in a single element of a document, found in strict or
+// lenient mode.
+type DecodeError struct {
+	// Line and Column are the one-based position of the start tag of the element
+	// in error, and Offset is its byte offset.
+	Line, Column int
+	Offset       int64
+	// Path locates the element, or one of its attributes, in the document, as in
+	// /tv/programme[3]/credits[1]/actor[2]/@guest. Indexes count the preceding
+	// siblings of the same name, starting from 1.
+	Path string
+	Err  error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("xmltv: line %d, column %d: %s: %v", e.Line, e.Column, e.Path, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeErrors is the list of every element skipped by a Decoder in lenient
+// mode.
+type DecodeErrors []*DecodeError
+
+func (e DecodeErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "xmltv: %d decoding errors", len(e))
+
+	for _, err := range e {
+		fmt.Fprintf(&b, "\n\tline %d, column %d: %s: %v", err.Line, err.Column, err.Path, err.Err)
+	}
+
+	return b.String()
+}
+
+func (e DecodeErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+
+	return errs
+}
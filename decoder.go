@@ -0,0 +1,512 @@
+package xmltv
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// Decoder reads an XMLTV document from an input stream one <channel> or
+// <programme> at a time, so that a guide never has to be held in memory in full.
+//
+// Channels and programmes are unmarshalled with the same struct tags and custom
+// Time and Bool handling used by xml.Unmarshal, so a streamed value is identical
+// to the corresponding element of a fully decoded TV.
+type Decoder struct {
+	d      *xml.Decoder
+	header *TV
+	peeked *xml.StartElement
+	err    error
+
+	zones      *TimeZones
+	programmes int
+	issues     []TimeZoneIssue
+
+	extensions []Extension
+
+	mode   DecodeMode
+	errors DecodeErrors
+	// start and path locate the element last returned by nextStart, counts holds
+	// the number of root children read so far by name.
+	start  position
+	path   string
+	counts map[string]int
+}
+
+// errSkipped is returned by decodeElement for an element skipped in lenient
+// mode.
+var errSkipped = errors.New("xmltv: element skipped")
+
+// NewDecoder returns a new decoder that reads from r. Documents in UTF-8 or in
+// one of the character sets supported by CharsetReader are accepted.
+func NewDecoder(r io.Reader) *Decoder {
+	d := xml.NewDecoder(r)
+	d.CharsetReader = CharsetReader
+
+	return &Decoder{d: d}
+}
+
+// SetTimeZones sets the locations in which programme times without an explicit
+// offset are interpreted, as TV.AugmentTimeZones does. Any DST issues found are
+// available from TimeZoneIssues.
+func (d *Decoder) SetTimeZones(zones TimeZones) {
+	d.zones = &zones
+}
+
+// TimeZoneIssues returns the DST issues found so far in the programmes decoded
+// with the time zones set by SetTimeZones.
+func (d *Decoder) TimeZoneIssues() []TimeZoneIssue {
+	return d.issues
+}
+
+// SetMode sets how content that does not conform to the DTD is treated. The
+// default is DecodeModeDefault.
+func (d *Decoder) SetMode(mode DecodeMode) {
+	d.mode = mode
+}
+
+// Errors returns the errors recorded so far for the elements skipped in lenient
+// mode.
+func (d *Decoder) Errors() DecodeErrors {
+	return d.errors
+}
+
+// Extensions returns the children of the root element not defined by the DTD
+// that have been read so far.
+func (d *Decoder) Extensions() []Extension {
+	return d.extensions
+}
+
+// Header returns the attributes of the root <tv> element. The returned TV has no
+// channels or programmes. The root element is read from the input on the first
+// call; later calls return the same value.
+func (d *Decoder) Header() (*TV, error) {
+	if d.header != nil {
+		return d.header, nil
+	}
+
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	for {
+		pos := d.position()
+
+		tok, err := d.d.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = errors.New("xmltv: missing <tv> root element")
+			}
+
+			d.err = err
+
+			return nil, err
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		if d.mode == DecodeModeStrict {
+			root := []positionedToken{{tok: start, pos: pos}, {tok: start.End(), pos: pos}}
+			if err := checkStrict(root, &TV{}, "/"+start.Name.Local); err != nil {
+				d.err = err
+
+				return nil, err
+			}
+		}
+
+		// Decode the root element without its children so that the attributes go
+		// through the regular TV struct tags.
+		var tv TV
+		if err := xml.NewTokenDecoder(&tokenSlice{start, start.End()}).Decode(&tv); err != nil {
+			if d.mode != DecodeModeLenient {
+				d.err = err
+
+				return nil, err
+			}
+
+			d.skipped(pos, "/"+start.Name.Local, err)
+
+			tv = TV{}
+		}
+
+		d.header = &tv
+
+		return &tv, nil
+	}
+}
+
+// Next returns the next <channel> or <programme> in the document as a *Channel or
+// a *Programme. Elements not defined by the DTD are collected for Extensions
+// rather than returned. Next returns io.EOF once the closing </tv> tag has been
+// read.
+func (d *Decoder) Next() (any, error) {
+	for {
+		start, err := d.nextStart()
+		if err != nil {
+			return nil, err
+		}
+
+		var v any
+
+		switch start.Name.Local {
+		case "channel":
+			c := &Channel{}
+			err = d.decodeElement(c, start)
+			v = c
+		default:
+			var p Programme
+			p, err = d.decodeProgramme(start)
+			v = &p
+		}
+
+		if errors.Is(err, errSkipped) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		return v, nil
+	}
+}
+
+// Channels returns an iterator over the channels of the document. Since the DTD
+// requires all channels to precede the programmes, iteration stops at the first
+// <programme>, which remains available to Programmes.
+func (d *Decoder) Channels() iter.Seq2[Channel, error] {
+	return func(yield func(Channel, error) bool) {
+		for {
+			start, err := d.nextStart()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+
+			if err != nil {
+				yield(Channel{}, err)
+
+				return
+			}
+
+			if start.Name.Local != "channel" {
+				d.peeked = start
+
+				return
+			}
+
+			var c Channel
+			if err := d.decodeElement(&c, start); errors.Is(err, errSkipped) {
+				continue
+			} else if err != nil {
+				yield(Channel{}, err)
+
+				return
+			}
+
+			if !yield(c, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Programmes returns an iterator over the programmes of the document. Channels
+// that have not yet been read through Channels or Next are discarded.
+func (d *Decoder) Programmes() iter.Seq2[Programme, error] {
+	return func(yield func(Programme, error) bool) {
+		for {
+			start, err := d.nextStart()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+
+			if err != nil {
+				yield(Programme{}, err)
+
+				return
+			}
+
+			if start.Name.Local != "programme" {
+				if err := d.skip(); err != nil {
+					yield(Programme{}, err)
+
+					return
+				}
+
+				continue
+			}
+
+			p, err := d.decodeProgramme(start)
+			if errors.Is(err, errSkipped) {
+				continue
+			}
+
+			if err != nil {
+				yield(Programme{}, err)
+
+				return
+			}
+
+			if !yield(p, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Decode reads the remainder of the document into tv. The root attributes and
+// all Extensions are always set, while only the channels and programmes not yet
+// read are appended. In lenient mode, tv holds every element that could be
+// decoded and the error, if any, is the DecodeErrors for those that could not.
+func (d *Decoder) Decode(tv *TV) error {
+	header, err := d.Header()
+	if err != nil {
+		return err
+	}
+
+	*tv = *header
+
+	for {
+		v, err := d.Next()
+		if errors.Is(err, io.EOF) {
+			tv.Extensions = d.extensions
+
+			if len(d.errors) > 0 {
+				return d.errors
+			}
+
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		switch v := v.(type) {
+		case *Channel:
+			tv.Channels = append(tv.Channels, *v)
+		case *Programme:
+			tv.Programmes = append(tv.Programmes, *v)
+		}
+	}
+}
+
+// nextStart returns the start element of the next <channel> or <programme>,
+// reading the root element first if needed. It returns io.EOF at the end of the
+// document.
+func (d *Decoder) nextStart() (*xml.StartElement, error) {
+	if _, err := d.Header(); err != nil {
+		return nil, err
+	}
+
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	if d.peeked != nil {
+		start := d.peeked
+		d.peeked = nil
+
+		return start, nil
+	}
+
+	for {
+		pos := d.position()
+
+		tok, err := d.d.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+
+			d.err = err
+
+			return nil, err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if d.counts == nil {
+				d.counts = make(map[string]int)
+			}
+
+			d.counts[tok.Name.Local]++
+			d.start = pos
+			d.path = fmt.Sprintf("/tv/%s[%d]", tok.Name.Local, d.counts[tok.Name.Local])
+
+			if tok.Name.Local == "channel" || tok.Name.Local == "programme" {
+				return &tok, nil
+			}
+
+			if d.mode == DecodeModeStrict {
+				d.err = &DecodeError{Line: pos.line, Column: pos.column, Offset: pos.offset, Path: d.path, Err: ErrUnknownElement}
+
+				return nil, d.err
+			}
+
+			var x Extension
+			if err := d.decodeElement(&x, &tok); err != nil {
+				return nil, err
+			}
+
+			pos := d.counts["channel"] + d.counts["programme"]
+			x.Position = &pos
+			d.extensions = append(d.extensions, x)
+		case xml.EndElement:
+			d.err = io.EOF
+
+			return nil, io.EOF
+		}
+	}
+}
+
+// decodeElement unmarshals the element beginning with start into v. In strict
+// mode the element is checked against the DTD first. In lenient mode an element
+// that cannot be decoded is recorded and errSkipped is returned.
+func (d *Decoder) decodeElement(v any, start *xml.StartElement) error {
+	if d.mode == DecodeModeDefault {
+		if err := d.d.DecodeElement(v, start); err != nil {
+			d.err = fmt.Errorf("xmltv: decoding <%s>: %w", start.Name.Local, err)
+
+			return d.err
+		}
+
+		return nil
+	}
+
+	tokens, err := d.readElement(start)
+	if err != nil {
+		return err
+	}
+
+	if d.mode == DecodeModeStrict {
+		if err := checkStrict(tokens, v, d.path); err != nil {
+			d.err = err
+
+			return err
+		}
+	}
+
+	s := make(tokenSlice, len(tokens))
+	for i, tok := range tokens {
+		s[i] = tok.tok
+	}
+
+	if err := xml.NewTokenDecoder(&s).Decode(v); err != nil {
+		if d.mode == DecodeModeLenient {
+			d.skipped(d.start, d.path, err)
+
+			return errSkipped
+		}
+
+		d.err = fmt.Errorf("xmltv: decoding <%s>: %w", start.Name.Local, err)
+
+		return d.err
+	}
+
+	return nil
+}
+
+// skipped records an error for the element at pos and path skipped in lenient
+// mode.
+func (d *Decoder) skipped(pos position, path string, err error) {
+	d.errors = append(d.errors, &DecodeError{Line: pos.line, Column: pos.column, Offset: pos.offset, Path: path, Err: err})
+}
+
+// readElement reads the remainder of the element beginning with start and
+// returns all of its tokens, including start, with their positions.
+func (d *Decoder) readElement(start *xml.StartElement) ([]positionedToken, error) {
+	tokens := []positionedToken{{tok: start.Copy(), pos: d.start}}
+
+	for depth := 1; depth > 0; {
+		pos := d.position()
+
+		tok, err := d.d.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+
+			d.err = err
+
+			return nil, err
+		}
+
+		switch tok.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+
+		tokens = append(tokens, positionedToken{tok: xml.CopyToken(tok), pos: pos})
+	}
+
+	return tokens, nil
+}
+
+// position returns the position of the next token in the input.
+func (d *Decoder) position() position {
+	line, column := d.d.InputPos()
+
+	return position{line: line, column: column, offset: d.d.InputOffset()}
+}
+
+// decodeProgramme unmarshals the programme beginning with start, applying the
+// time zones set by SetTimeZones.
+func (d *Decoder) decodeProgramme(start *xml.StartElement) (Programme, error) {
+	var p Programme
+	if err := d.decodeElement(&p, start); err != nil {
+		return Programme{}, err
+	}
+
+	if d.zones != nil {
+		d.issues = append(d.issues, d.zones.augmentProgramme(&p, d.programmes)...)
+	}
+
+	d.programmes++
+
+	return p, nil
+}
+
+// skip discards the element whose start tag was just read.
+func (d *Decoder) skip() error {
+	if err := d.d.Skip(); err != nil {
+		d.err = err
+
+		return err
+	}
+
+	return nil
+}
+
+// position is the location of a token in the input.
+type position struct {
+	line, column int
+	offset       int64
+}
+
+// positionedToken is a token with the position at which it starts.
+type positionedToken struct {
+	tok xml.Token
+	pos position
+}
+
+// tokenSlice is an xml.TokenReader over a fixed sequence of tokens.
+type tokenSlice []xml.Token
+
+func (s *tokenSlice) Token() (xml.Token, error) {
+	if len(*s) == 0 {
+		return nil, io.EOF
+	}
+
+	tok := (*s)[0]
+	*s = (*s)[1:]
+
+	return tok, nil
+}
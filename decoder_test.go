@@ -0,0 +1,120 @@
+package xmltv
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDecoder(t *testing.T) {
+	t.Parallel()
+
+	data, err := os.ReadFile("testdata/unmarshal/epg.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want TV
+	if err := xml.Unmarshal(data, &want); err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewDecoder(strings.NewReader(string(data)))
+
+	header, err := d.Header()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := *header
+
+	for c, err := range d.Channels() {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got.Channels = append(got.Channels, c)
+	}
+
+	for p, err := range d.Programmes() {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got.Programmes = append(got.Programmes, p)
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatal(diff)
+	}
+
+	if _, err := d.Next(); !errors.Is(err, io.EOF) {
+		t.Fatalf("got %v, want io.EOF", err)
+	}
+}
+
+func TestDecoderDecode(t *testing.T) {
+	t.Parallel()
+
+	data, err := os.ReadFile("testdata/unmarshal/epg-empty-date.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want TV
+	if err := xml.Unmarshal(data, &want); err != nil {
+		t.Fatal(err)
+	}
+
+	var got TV
+	if err := NewDecoder(strings.NewReader(string(data))).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestDecoderProgrammesOnly(t *testing.T) {
+	t.Parallel()
+
+	doc := `<tv><channel id="a"><display-name>A</display-name></channel>` +
+		`<programme start="20220331180000 +0000" channel="a"><title>One</title></programme>` +
+		`<programme start="20220331190000 +0000" channel="a"><title>Two</title></programme></tv>`
+
+	var titles []string
+
+	for p, err := range NewDecoder(strings.NewReader(doc)).Programmes() {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		titles = append(titles, p.Titles[0].Text)
+	}
+
+	if diff := cmp.Diff([]string{"One", "Two"}, titles); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestDecoderInvalidProgramme(t *testing.T) {
+	t.Parallel()
+
+	doc := `<tv><programme start="not-a-date" channel="a"><title>One</title></programme></tv>`
+
+	var gotErr error
+
+	for _, err := range NewDecoder(strings.NewReader(doc)).Programmes() {
+		gotErr = err
+	}
+
+	if gotErr == nil {
+		t.Fatal("expected error decoding invalid programme, got nil")
+	}
+}
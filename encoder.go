@@ -0,0 +1,347 @@
+package xmltv
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DocType is the document type declaration referencing the XMLTV DTD.
+const DocType = `<!DOCTYPE tv SYSTEM "xmltv.dtd">`
+
+var (
+	// ErrChannelAfterProgramme is returned by Encoder.WriteChannel once a programme
+	// has been written, since the DTD requires all channels to come first.
+	ErrChannelAfterProgramme = errors.New("xmltv: channel written after programme")
+
+	// ErrEncoderClosed is returned when writing to an Encoder after Close.
+	ErrEncoderClosed = errors.New("xmltv: encoder closed")
+)
+
+type encoderState int
+
+const (
+	encoderStateInitial encoderState = iota
+	encoderStateChannels
+	encoderStateProgrammes
+	encoderStateClosed
+)
+
+// Encoder writes an XMLTV document to an output stream one channel or programme
+// at a time, so that a guide never has to be held in memory in full.
+type Encoder struct {
+	out        io.Writer
+	w          io.Writer
+	e          *xml.Encoder
+	compressor io.WriteCloser
+	charset    *charset
+	prefix     string
+	indent     string
+	docType    bool
+	stylesheet string
+	timeFormat TimeFormat
+	state      encoderState
+	// extensions holds the root extensions not yet written, and written the
+	// number of channels and programmes written so far.
+	extensions []Extension
+	written    int
+}
+
+// NewEncoder returns a new encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	enc := &Encoder{out: w}
+	enc.setWriter()
+
+	return enc
+}
+
+// Indent sets the encoder to generate XML in which each element begins on a new
+// indented line that starts with prefix and is followed by one or more copies of
+// indent according to the nesting depth.
+func (enc *Encoder) Indent(prefix, indent string) {
+	enc.prefix, enc.indent = prefix, indent
+	enc.e.Indent(prefix, indent)
+}
+
+// setWriter sets up the chain of writers from the XML encoder to the underlying
+// writer, through the compressor and charset conversion if set.
+func (enc *Encoder) setWriter() {
+	w := enc.out
+	if enc.compressor != nil {
+		w = enc.compressor
+	}
+
+	if enc.charset != nil {
+		w = &charsetWriter{w: w, charmap: enc.charset.charmap}
+	}
+
+	enc.w = w
+	enc.e = xml.NewEncoder(w)
+	enc.e.Indent(enc.prefix, enc.indent)
+}
+
+// SetDocType sets whether the document type declaration DocType is written after
+// the XML declaration.
+func (enc *Encoder) SetDocType(docType bool) {
+	enc.docType = docType
+}
+
+// SetStylesheet sets the href of the style sheet referenced by an
+// <?xml-stylesheet?> processing instruction written after the XML declaration.
+// The type is text/css for an href ending in .css and text/xsl otherwise. An
+// empty href, the default, writes no processing instruction.
+func (enc *Encoder) SetStylesheet(href string) {
+	enc.stylesheet = href
+}
+
+// stylesheetPI returns the <?xml-stylesheet?> processing instruction referencing
+// href.
+func stylesheetPI(href string) string {
+	typ := "text/xsl"
+	if strings.HasSuffix(strings.ToLower(href), ".css") {
+		typ = "text/css"
+	}
+
+	var b strings.Builder
+
+	b.WriteString(`<?xml-stylesheet type="` + typ + `" href="`)
+	xml.EscapeText(&b, []byte(href))
+	b.WriteString(`"?>`)
+
+	return b.String()
+}
+
+// SetTimeFormat sets the layout and location that the Start, Stop, PDCStart,
+// VPSStart and PreviouslyShown.Start times of every written programme are
+// converted to. The zero TimeFormat, the default, writes each time as is.
+func (enc *Encoder) SetTimeFormat(f TimeFormat) error {
+	if err := f.validate(); err != nil {
+		return err
+	}
+
+	enc.timeFormat = f
+
+	return nil
+}
+
+// WriteHeader writes the XML declaration, the optional DOCTYPE and the root <tv>
+// start tag carrying the attributes of tv. The channels and programmes of tv are
+// not written, while each of its Extensions is written before the channel or
+// programme at its Position, or by Close. Calling WriteHeader is
+// optional: if WriteChannel, WriteProgramme or Close is called first, a root
+// element without attributes is written.
+func (enc *Encoder) WriteHeader(tv *TV) error {
+	if enc.state != encoderStateInitial {
+		if enc.state == encoderStateClosed {
+			return ErrEncoderClosed
+		}
+
+		return errors.New("xmltv: header already written")
+	}
+
+	start, err := rootStart(tv)
+	if err != nil {
+		return err
+	}
+
+	prolog := xml.Header
+	if enc.charset != nil {
+		prolog = fmt.Sprintf(`<?xml version="1.0" encoding="%s"?>`+"\n", enc.charset.name)
+	}
+
+	if enc.stylesheet != "" {
+		prolog += stylesheetPI(enc.stylesheet) + "\n"
+	}
+
+	if enc.docType {
+		prolog += DocType + "\n"
+	}
+
+	if _, err := io.WriteString(enc.w, prolog); err != nil {
+		return err
+	}
+
+	if err := enc.e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	enc.state = encoderStateChannels
+	enc.extensions = tv.Extensions
+
+	return nil
+}
+
+// WriteChannel writes c. It returns ErrChannelAfterProgramme if a programme has
+// already been written.
+func (enc *Encoder) WriteChannel(c Channel) error {
+	if err := enc.ensureHeader(); err != nil {
+		return err
+	}
+
+	if enc.state == encoderStateProgrammes {
+		return ErrChannelAfterProgramme
+	}
+
+	if err := enc.writeExtensions(); err != nil {
+		return err
+	}
+
+	enc.written++
+
+	return enc.e.Encode(&c)
+}
+
+// WriteProgramme writes p.
+func (enc *Encoder) WriteProgramme(p Programme) error {
+	if err := enc.ensureHeader(); err != nil {
+		return err
+	}
+
+	enc.state = encoderStateProgrammes
+
+	enc.timeFormat.applyProgramme(&p)
+
+	if err := enc.writeExtensions(); err != nil {
+		return err
+	}
+
+	enc.written++
+
+	return enc.e.Encode(&p)
+}
+
+// writeExtensions writes the pending root extensions whose Position is at most
+// the number of channels and programmes written so far.
+func (enc *Encoder) writeExtensions() error {
+	var rest []Extension
+
+	for _, x := range enc.extensions {
+		if x.Position == nil || *x.Position > enc.written {
+			rest = append(rest, x)
+
+			continue
+		}
+
+		if err := enc.e.Encode(&x); err != nil {
+			return err
+		}
+	}
+
+	enc.extensions = rest
+
+	return nil
+}
+
+// Encode writes the whole of tv, its header, channels and programmes, and closes
+// the encoder.
+func (enc *Encoder) Encode(tv *TV) error {
+	if err := enc.WriteHeader(tv); err != nil {
+		return err
+	}
+
+	for _, c := range tv.Channels {
+		if err := enc.WriteChannel(c); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range tv.Programmes {
+		if err := enc.WriteProgramme(p); err != nil {
+			return err
+		}
+	}
+
+	return enc.Close()
+}
+
+// Close writes the root Extensions given to WriteHeader that have not been
+// written yet, then the closing </tv> tag. It flushes the
+// output and finishes any compressed stream, but does not close the underlying
+// writer.
+func (enc *Encoder) Close() error {
+	if err := enc.ensureHeader(); err != nil {
+		return err
+	}
+
+	enc.state = encoderStateClosed
+
+	for _, x := range enc.extensions {
+		if err := enc.e.Encode(&x); err != nil {
+			return err
+		}
+	}
+
+	if err := enc.e.EncodeToken(xml.EndElement{Name: xml.Name{Local: "tv"}}); err != nil {
+		return err
+	}
+
+	if err := enc.e.Close(); err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(enc.w, "\n"); err != nil {
+		return err
+	}
+
+	if enc.compressor != nil {
+		return enc.compressor.Close()
+	}
+
+	return nil
+}
+
+// ensureHeader writes a bare header if none has been written yet.
+func (enc *Encoder) ensureHeader() error {
+	switch enc.state {
+	case encoderStateInitial:
+		return enc.WriteHeader(&TV{})
+	case encoderStateClosed:
+		return ErrEncoderClosed
+	default:
+		return nil
+	}
+}
+
+// rootStart returns the <tv> start element carrying the root attributes of tv.
+func rootStart(tv *TV) (xml.StartElement, error) {
+	start := xml.StartElement{Name: xml.Name{Local: "tv"}}
+
+	if tv.Date != nil {
+		attr, err := tv.Date.MarshalXMLAttr(xml.Name{Local: "date"})
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+
+		if attr.Name.Local != "" {
+			start.Attr = append(start.Attr, attr)
+		}
+	}
+
+	for _, attr := range []struct {
+		name  string
+		value *string
+	}{
+		{"source-info-url", tv.SourceInfoURL},
+		{"source-info-name", tv.SourceInfoName},
+		{"source-data-url", tv.SourceDataURL},
+		{"generator-info-name", tv.GeneratorInfoName},
+		{"generator-info-url", tv.GeneratorInfoURL},
+	} {
+		if attr.value != nil {
+			start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: attr.name}, Value: *attr.value})
+		}
+	}
+
+	for _, a := range tv.Attrs {
+		attr, err := a.MarshalXMLAttr(a.Name)
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+
+		start.Attr = append(start.Attr, attr)
+	}
+
+	return start, nil
+}
@@ -0,0 +1,189 @@
+package xmltv
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEncoder(t *testing.T) {
+	t.Parallel()
+
+	data, err := os.ReadFile("testdata/unmarshal/epg.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tv TV
+	if err := xml.Unmarshal(data, &tv); err != nil {
+		t.Fatal(err)
+	}
+
+	marshalled, err := xml.Marshal(tv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := xml.Header + DocType + "\n" + string(marshalled) + "\n"
+
+	var buf bytes.Buffer
+
+	enc := NewEncoder(&buf)
+	enc.SetDocType(true)
+
+	if err := enc.WriteHeader(&tv); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range tv.Channels {
+		if err := enc.WriteChannel(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, p := range tv.Programmes {
+		if err := enc.WriteProgramme(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestEncoderChannelAfterProgramme(t *testing.T) {
+	t.Parallel()
+
+	enc := NewEncoder(&bytes.Buffer{})
+
+	if err := enc.WriteProgramme(Programme{Channel: "a", Titles: []Title{{Text: "One"}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := enc.WriteChannel(Channel{ID: "a"}); !errors.Is(err, ErrChannelAfterProgramme) {
+		t.Fatalf("got %v, want ErrChannelAfterProgramme", err)
+	}
+}
+
+func TestEncoderEmpty(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	if err := NewEncoder(&buf).Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := xml.Header + "<tv></tv>\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestEncoderTimeFormat(t *testing.T) {
+	t.Parallel()
+
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	stop := &Time{Time: parseTime(t, "20060102150405 -0700", "20220331190000 +0000")}
+	original := *stop
+	p := Programme{
+		Start:           Time{Time: parseTime(t, "20060102150405 -0700", "20220331180000 +0000")},
+		Stop:            stop,
+		Channel:         "a",
+		Titles:          []Title{{Text: "One"}},
+		PreviouslyShown: &PreviouslyShown{Start: &Time{Time: parseTime(t, "2006", "2021")}},
+	}
+
+	var buf bytes.Buffer
+
+	enc := NewEncoder(&buf)
+	if err := enc.SetTimeFormat(TimeFormat{Layout: "200601021504 -0700", Location: loc}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := enc.WriteProgramme(p); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := xml.Header + `<tv><programme start="202203311400 -0400" stop="202203311500 -0400" channel="a">` +
+		`<title>One</title><previously-shown start="202012311900 -0500"></previously-shown></programme></tv>` + "\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Fatal(diff)
+	}
+
+	if *stop != original {
+		t.Fatal("SetTimeFormat modified the caller's programme")
+	}
+
+	if err := NewEncoder(&buf).SetTimeFormat(TimeFormat{Layout: time.RFC3339}); err == nil {
+		t.Fatal("expected error setting unsupported layout, got nil")
+	}
+}
+
+func TestEncoderTimeFormatAddsOffset(t *testing.T) {
+	t.Parallel()
+
+	loc, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	tests := []struct {
+		name string
+		f    TimeFormat
+		want string
+	}{
+		{name: "own layout", f: TimeFormat{Location: loc}, want: `start="20220101130000 +0100"`},
+		{name: "layout without offset", f: TimeFormat{Layout: "200601021504", Location: loc}, want: `start="202201011300 +0100"`},
+		{name: "no location", f: TimeFormat{}, want: `start="20220101120000"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var p Programme
+			if err := xml.Unmarshal([]byte(`<programme start="20220101120000" channel="a"><title>One</title></programme>`), &p); err != nil {
+				t.Fatal(err)
+			}
+
+			var buf bytes.Buffer
+
+			enc := NewEncoder(&buf)
+			if err := enc.SetTimeFormat(tt.f); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := enc.WriteProgramme(p); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := enc.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("got %s, want %s", buf.String(), tt.want)
+			}
+		})
+	}
+}
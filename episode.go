@@ -0,0 +1,252 @@
+package xmltv
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Episode number systems with a well-known format.
+const (
+	EpisodeNumberSystemXMLTVNS  = "xmltv_ns"
+	EpisodeNumberSystemOnScreen = "onscreen"
+	EpisodeNumberSystemDDProgID = "dd_progid"
+)
+
+// ErrUnsupportedEpisodeSystem is returned when parsing an episode number whose
+// system has no known format.
+var ErrUnsupportedEpisodeSystem = errors.New("xmltv: unsupported episode-num system")
+
+// Episode is the structured form of an episode number. Unlike the zero-based
+// xmltv_ns system, Season, Episode and Part are one-based. A nil field means the
+// value is unknown.
+type Episode struct {
+	Season        *int
+	TotalSeasons  *int
+	Episode       *int
+	TotalEpisodes *int
+	Part          *int
+	TotalParts    *int
+}
+
+// ProgID is the structured form of a dd_progid episode number such as
+// 'EP00003026.0666'.
+type ProgID struct {
+	// Kind is the two letter programme type, e.g. 'EP' for an episode or 'MV' for
+	// a movie.
+	Kind string
+	// Series identifies the series or the programme itself.
+	Series string
+	// Episode is the episode sequence number within the series, or 0 if none.
+	Episode int
+}
+
+var (
+	onScreenPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)^S(?P<season>\d+)\s*E(?P<episode>\d+)$`),
+		regexp.MustCompile(`(?i)^(?P<season>\d+)\s*x\s*(?P<episode>\d+)$`),
+		regexp.MustCompile(`(?i)^Season\s*(?P<season>\d+),?\s*Episode\s*(?P<episode>\d+)$`),
+		regexp.MustCompile(`(?i)^(?:Episode|Ep\.?|E)\s*(?P<episode>\d+)$`),
+	}
+
+	progIDPattern = regexp.MustCompile(`^([A-Z]{2})(\d+)\.(\d+)$`)
+)
+
+// Episode parses the episode number according to its System. The xmltv_ns,
+// onscreen and dd_progid systems are supported; an empty System is treated as
+// onscreen, its DTD default. For dd_progid only the episode sequence number is
+// reported; use ProgID for the full identifier.
+func (n EpisodeNumber) Episode() (Episode, error) {
+	switch n.System {
+	case EpisodeNumberSystemXMLTVNS:
+		return parseXMLTVNS(n.Text)
+	case EpisodeNumberSystemOnScreen, "":
+		return parseOnScreen(n.Text)
+	case EpisodeNumberSystemDDProgID:
+		id, err := n.ProgID()
+		if err != nil {
+			return Episode{}, err
+		}
+
+		if id.Episode == 0 {
+			return Episode{}, nil
+		}
+
+		return Episode{Episode: &id.Episode}, nil
+	default:
+		return Episode{}, fmt.Errorf("%w %q", ErrUnsupportedEpisodeSystem, n.System)
+	}
+}
+
+// ProgID parses a dd_progid episode number.
+func (n EpisodeNumber) ProgID() (ProgID, error) {
+	if n.System != EpisodeNumberSystemDDProgID {
+		return ProgID{}, fmt.Errorf("xmltv: episode-num system %q is not %s", n.System, EpisodeNumberSystemDDProgID)
+	}
+
+	m := progIDPattern.FindStringSubmatch(strings.TrimSpace(n.Text))
+	if m == nil {
+		return ProgID{}, fmt.Errorf("xmltv: invalid dd_progid episode number %q", n.Text)
+	}
+
+	episode, err := strconv.Atoi(m[3])
+	if err != nil {
+		return ProgID{}, fmt.Errorf("xmltv: invalid dd_progid episode number %q: %w", n.Text, err)
+	}
+
+	return ProgID{Kind: m[1], Series: m[2], Episode: episode}, nil
+}
+
+// XMLTVNS returns e as a canonical xmltv_ns episode number such as '0.4.0/2'.
+func (e Episode) XMLTVNS() (EpisodeNumber, error) {
+	if err := e.validate(); err != nil {
+		return EpisodeNumber{}, err
+	}
+
+	parts := make([]string, 3)
+	for i, v := range [][2]*int{{e.Season, e.TotalSeasons}, {e.Episode, e.TotalEpisodes}, {e.Part, e.TotalParts}} {
+		if v[0] != nil {
+			parts[i] = strconv.Itoa(*v[0] - 1)
+		}
+
+		if v[1] != nil {
+			parts[i] += "/" + strconv.Itoa(*v[1])
+		}
+	}
+
+	return EpisodeNumber{System: EpisodeNumberSystemXMLTVNS, Text: strings.Join(parts, ".")}, nil
+}
+
+// OnScreen returns e as a canonical onscreen episode number such as 'S01E05'.
+// Parts and totals have no onscreen form and are omitted.
+func (e Episode) OnScreen() (EpisodeNumber, error) {
+	if err := e.validate(); err != nil {
+		return EpisodeNumber{}, err
+	}
+
+	if e.Season == nil && e.Episode == nil {
+		return EpisodeNumber{}, errors.New("xmltv: onscreen episode number needs a season or an episode")
+	}
+
+	var b strings.Builder
+	if e.Season != nil {
+		fmt.Fprintf(&b, "S%02d", *e.Season)
+	}
+
+	if e.Episode != nil {
+		fmt.Fprintf(&b, "E%02d", *e.Episode)
+	}
+
+	return EpisodeNumber{System: EpisodeNumberSystemOnScreen, Text: b.String()}, nil
+}
+
+// validate checks that every number is positive and within its total.
+func (e Episode) validate() error {
+	for _, v := range []struct {
+		name         string
+		value, total *int
+	}{
+		{"season", e.Season, e.TotalSeasons},
+		{"episode", e.Episode, e.TotalEpisodes},
+		{"part", e.Part, e.TotalParts},
+	} {
+		if v.value != nil && *v.value < 1 {
+			return fmt.Errorf("xmltv: %s %d is not positive", v.name, *v.value)
+		}
+
+		if v.total != nil && *v.total < 1 {
+			return fmt.Errorf("xmltv: %s total %d is not positive", v.name, *v.total)
+		}
+
+		if v.value != nil && v.total != nil && *v.value > *v.total {
+			return fmt.Errorf("xmltv: %s %d exceeds total %d", v.name, *v.value, *v.total)
+		}
+	}
+
+	return nil
+}
+
+// parseXMLTVNS parses an xmltv_ns episode number of the form
+// 'season/total . episode/total . part/total', where every number and total is
+// optional, numbers are zero-based and totals are one-based.
+func parseXMLTVNS(text string) (Episode, error) {
+	fields := strings.Split(text, ".")
+	if len(fields) > 3 {
+		return Episode{}, fmt.Errorf("xmltv: invalid xmltv_ns episode number %q: too many parts", text)
+	}
+
+	var e Episode
+
+	targets := [][2]**int{{&e.Season, &e.TotalSeasons}, {&e.Episode, &e.TotalEpisodes}, {&e.Part, &e.TotalParts}}
+	for i, field := range fields {
+		value, total, _ := strings.Cut(field, "/")
+
+		if value = strings.TrimSpace(value); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return Episode{}, fmt.Errorf("xmltv: invalid xmltv_ns episode number %q: bad number %q", text, value)
+			}
+
+			n++
+			*targets[i][0] = &n
+		}
+
+		if total = strings.TrimSpace(total); total != "" {
+			n, err := strconv.Atoi(total)
+			if err != nil || n < 1 {
+				return Episode{}, fmt.Errorf("xmltv: invalid xmltv_ns episode number %q: bad total %q", text, total)
+			}
+
+			*targets[i][1] = &n
+		}
+	}
+
+	if err := e.validate(); err != nil {
+		return Episode{}, fmt.Errorf("xmltv: invalid xmltv_ns episode number %q: %w", text, err)
+	}
+
+	return e, nil
+}
+
+// parseOnScreen parses the common onscreen forms 'S01E05', '1x05',
+// 'Season 1 Episode 5' and 'Episode 5'.
+func parseOnScreen(text string) (Episode, error) {
+	trimmed := strings.TrimSpace(text)
+
+	for _, pattern := range onScreenPatterns {
+		m := pattern.FindStringSubmatch(trimmed)
+		if m == nil {
+			continue
+		}
+
+		var e Episode
+
+		for i, name := range pattern.SubexpNames() {
+			if name == "" {
+				continue
+			}
+
+			n, err := strconv.Atoi(m[i])
+			if err != nil {
+				return Episode{}, fmt.Errorf("xmltv: invalid onscreen episode number %q: %w", text, err)
+			}
+
+			switch name {
+			case "season":
+				e.Season = &n
+			case "episode":
+				e.Episode = &n
+			}
+		}
+
+		if err := e.validate(); err != nil {
+			return Episode{}, fmt.Errorf("xmltv: invalid onscreen episode number %q: %w", text, err)
+		}
+
+		return e, nil
+	}
+
+	return Episode{}, fmt.Errorf("xmltv: unrecognised onscreen episode number %q", text)
+}
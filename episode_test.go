@@ -0,0 +1,136 @@
+package xmltv
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEpisodeNumberEpisode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		number EpisodeNumber
+		want   Episode
+	}{
+		{
+			name:   "xmltv_ns full",
+			number: EpisodeNumber{System: "xmltv_ns", Text: "1 . 1 . 0/1"},
+			want:   Episode{Season: makePointer(2), Episode: makePointer(2), Part: makePointer(1), TotalParts: makePointer(1)},
+		},
+		{
+			name:   "xmltv_ns with totals",
+			number: EpisodeNumber{System: "xmltv_ns", Text: "0/4.11/13."},
+			want:   Episode{Season: makePointer(1), TotalSeasons: makePointer(4), Episode: makePointer(12), TotalEpisodes: makePointer(13)},
+		},
+		{
+			name:   "xmltv_ns episode only",
+			number: EpisodeNumber{System: "xmltv_ns", Text: ".4."},
+			want:   Episode{Episode: makePointer(5)},
+		},
+		{
+			name:   "onscreen SxxEyy",
+			number: EpisodeNumber{System: "onscreen", Text: "S01E05"},
+			want:   Episode{Season: makePointer(1), Episode: makePointer(5)},
+		},
+		{
+			name:   "onscreen NxNN",
+			number: EpisodeNumber{System: "onscreen", Text: "1x05"},
+			want:   Episode{Season: makePointer(1), Episode: makePointer(5)},
+		},
+		{
+			name:   "onscreen episode",
+			number: EpisodeNumber{Text: "Episode 5"},
+			want:   Episode{Episode: makePointer(5)},
+		},
+		{
+			name:   "dd_progid",
+			number: EpisodeNumber{System: "dd_progid", Text: "EP00003026.0666"},
+			want:   Episode{Episode: makePointer(666)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tt.number.Episode()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestEpisodeNumberEpisodeInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		number EpisodeNumber
+	}{
+		{name: "xmltv_ns too many parts", number: EpisodeNumber{System: "xmltv_ns", Text: "1.2.3.4"}},
+		{name: "xmltv_ns not a number", number: EpisodeNumber{System: "xmltv_ns", Text: "a.2."}},
+		{name: "xmltv_ns exceeds total", number: EpisodeNumber{System: "xmltv_ns", Text: ".2/2."}},
+		{name: "onscreen unrecognised", number: EpisodeNumber{System: "onscreen", Text: "Pilot"}},
+		{name: "dd_progid malformed", number: EpisodeNumber{System: "dd_progid", Text: "EP0666"}},
+		{name: "unsupported system", number: EpisodeNumber{System: "imdb.com", Text: "tt0000001"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := tt.number.Episode(); err == nil {
+				t.Fatalf("expected error parsing %q, got nil", tt.number.Text)
+			}
+		})
+	}
+
+	if _, err := (EpisodeNumber{System: "imdb.com"}).Episode(); !errors.Is(err, ErrUnsupportedEpisodeSystem) {
+		t.Fatalf("got %v, want ErrUnsupportedEpisodeSystem", err)
+	}
+}
+
+func TestEpisodeRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	e := Episode{Season: makePointer(2), Episode: makePointer(5), TotalEpisodes: makePointer(13), Part: makePointer(1), TotalParts: makePointer(2)}
+
+	ns, err := e.XMLTVNS()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(EpisodeNumber{System: "xmltv_ns", Text: "1.4/13.0/2"}, ns); diff != "" {
+		t.Fatal(diff)
+	}
+
+	got, err := ns.Episode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(e, got); diff != "" {
+		t.Fatal(diff)
+	}
+
+	onScreen, err := e.OnScreen()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(EpisodeNumber{System: "onscreen", Text: "S02E05"}, onScreen); diff != "" {
+		t.Fatal(diff)
+	}
+
+	if _, err := (Episode{Episode: makePointer(0)}).XMLTVNS(); err == nil {
+		t.Fatal("expected error building episode 0, got nil")
+	}
+}
@@ -0,0 +1,336 @@
+package xmltv
+
+import (
+	"encoding/xml"
+	"reflect"
+)
+
+// Attr is an attribute not defined by the XMLTV DTD, such as a vendor's tvg-id,
+// kept so that it survives a decode and encode round trip.
+type Attr xml.Attr
+
+// UnmarshalXMLAttr implements xml.UnmarshalerAttr.
+func (a *Attr) UnmarshalXMLAttr(attr xml.Attr) error {
+	*a = Attr(attr)
+
+	return nil
+}
+
+// MarshalXMLAttr implements xml.MarshalerAttr. Namespace declarations are
+// written back as they were read, rather than as attributes in the xmlns
+// namespace.
+func (a Attr) MarshalXMLAttr(xml.Name) (xml.Attr, error) {
+	if a.Name.Space == "xmlns" {
+		return xml.Attr{Name: xml.Name{Local: "xmlns:" + a.Name.Local}, Value: a.Value}, nil
+	}
+
+	return xml.Attr(a), nil
+}
+
+// Extension is an element not defined by the XMLTV DTD, such as a vendor's
+// catchup ID, kept so that its content survives a decode and encode round trip.
+//
+// A namespace prefix is not kept: <foo:x xmlns:foo="urn:y"> is written as
+// <x xmlns="urn:y" xmlns:foo="urn:y">, which is equivalent XML but not the same
+// text.
+type Extension struct {
+	XMLName xml.Name
+	Attrs   []Attr `xml:",any,attr"`
+	// InnerXML is the raw content of the element.
+	InnerXML string `xml:",innerxml"`
+	// Position is the number of sibling elements defined by the DTD that
+	// preceded the extension when it was read, and that are written before it.
+	// Root extensions count the channels and programmes. If Position is nil, or
+	// greater than the number of siblings, the extension is written after them.
+	Position *int `xml:"-"`
+}
+
+// UnmarshalXML implements xml.Unmarshaler, recording the Position of each
+// extension.
+func (tv *TV) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type tvAlias TV
+
+	return unmarshalExtended(d, start, (*tvAlias)(tv))
+}
+
+// MarshalXML implements xml.Marshaler, writing each extension at its Position.
+func (tv *TV) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type tvAlias TV
+
+	return marshalExtended(e, start, (*tvAlias)(tv))
+}
+
+// UnmarshalXML implements xml.Unmarshaler, recording the Position of each
+// extension.
+func (c *Channel) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type channelAlias Channel
+
+	return unmarshalExtended(d, start, (*channelAlias)(c))
+}
+
+// MarshalXML implements xml.Marshaler, writing each extension at its Position.
+func (c *Channel) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type channelAlias Channel
+
+	return marshalExtended(e, start, (*channelAlias)(c))
+}
+
+// UnmarshalXML implements xml.Unmarshaler, recording the Position of each
+// extension.
+func (p *Programme) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type programmeAlias Programme
+
+	return unmarshalExtended(d, start, (*programmeAlias)(p))
+}
+
+// MarshalXML implements xml.Marshaler, writing each extension at its Position.
+func (p *Programme) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type programmeAlias Programme
+
+	return marshalExtended(e, start, (*programmeAlias)(p))
+}
+
+// unmarshalExtended decodes the element beginning with start into v, a pointer
+// to a struct without methods that has an Extensions field. Known children are
+// decoded into their fields as encoding/xml would; the others are appended to
+// Extensions along with the number of known children before them.
+func unmarshalExtended(d *xml.Decoder, start xml.StartElement, v any) error {
+	// Decode the start tag alone so that the attributes go through the regular
+	// struct tags.
+	if err := xml.NewTokenDecoder(&tokenSlice{start, start.End()}).Decode(v); err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(v).Elem()
+	f := fieldsOf(rv.Type())
+	exts := rv.FieldByName("Extensions")
+
+	children := make(map[string]xmlField)
+
+	for _, field := range f.fields {
+		if !field.attr {
+			children[field.name] = field
+		}
+	}
+
+	known := 0
+
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			field, ok := children[tok.Name.Local]
+			if !ok {
+				var x Extension
+				if err := d.DecodeElement(&x, &tok); err != nil {
+					return err
+				}
+
+				pos := known
+				x.Position = &pos
+				exts.Set(reflect.Append(exts, reflect.ValueOf(x)))
+
+				continue
+			}
+
+			fv := rv.Field(field.index)
+
+			switch fv.Kind() {
+			case reflect.Slice:
+				elem := reflect.New(fv.Type().Elem())
+				if err := d.DecodeElement(elem.Interface(), &tok); err != nil {
+					return err
+				}
+
+				fv.Set(reflect.Append(fv, elem.Elem()))
+			case reflect.Pointer:
+				if fv.IsNil() {
+					fv.Set(reflect.New(fv.Type().Elem()))
+				}
+
+				if err := d.DecodeElement(fv.Interface(), &tok); err != nil {
+					return err
+				}
+			default:
+				if err := d.DecodeElement(fv.Addr().Interface(), &tok); err != nil {
+					return err
+				}
+			}
+
+			known++
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// marshalExtended writes v, a pointer to a struct without methods that has an
+// Extensions field, as the element named by its XMLName tag, interleaving the
+// extensions with the known children according to their Position.
+func marshalExtended(e *xml.Encoder, start xml.StartElement, v any) error {
+	rv := reflect.ValueOf(v).Elem()
+	f := fieldsOf(rv.Type())
+	exts := rv.FieldByName("Extensions").Interface().([]Extension)
+
+	// Name the element after the struct tag rather than the Go type, as
+	// encoding/xml does when it has no field tag to go by.
+	start.Name = xml.Name{Local: f.name}
+
+	positioned := false
+
+	for _, x := range exts {
+		positioned = positioned || x.Position != nil
+	}
+
+	if !positioned {
+		return e.EncodeElement(v, start)
+	}
+
+	attrs, err := marshalAttrs(rv, f)
+	if err != nil {
+		return err
+	}
+
+	start.Attr = append(start.Attr, attrs...)
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	written := 0
+	pending := exts
+
+	// flush writes the pending extensions positioned at or before the number of
+	// known children written so far.
+	flush := func() error {
+		var rest []Extension
+
+		for _, x := range pending {
+			if x.Position == nil || *x.Position > written {
+				rest = append(rest, x)
+
+				continue
+			}
+
+			if err := e.Encode(&x); err != nil {
+				return err
+			}
+		}
+
+		pending = rest
+
+		return nil
+	}
+
+	encode := func(child any, name string) error {
+		if err := flush(); err != nil {
+			return err
+		}
+
+		written++
+
+		return e.EncodeElement(child, xml.StartElement{Name: xml.Name{Local: name}})
+	}
+
+	for _, field := range f.fields {
+		if field.attr {
+			continue
+		}
+
+		fv := rv.Field(field.index)
+
+		switch {
+		case fv.Kind() == reflect.Slice:
+			for i := range fv.Len() {
+				if err := encode(fv.Index(i).Addr().Interface(), field.name); err != nil {
+					return err
+				}
+			}
+		case fv.Kind() == reflect.Pointer:
+			if fv.IsNil() {
+				continue
+			}
+
+			if err := encode(fv.Interface(), field.name); err != nil {
+				return err
+			}
+		case field.omitempty && fv.IsZero():
+		default:
+			if err := encode(fv.Addr().Interface(), field.name); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, x := range pending {
+		if err := e.Encode(&x); err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// marshalAttrs returns the attributes of rv, a struct described by f, as
+// encoding/xml would write them.
+func marshalAttrs(rv reflect.Value, f *elementFields) ([]xml.Attr, error) {
+	var attrs []xml.Attr
+
+	for _, field := range f.fields {
+		if !field.attr {
+			continue
+		}
+
+		fv := rv.Field(field.index)
+
+		if field.any {
+			for _, a := range fv.Interface().([]Attr) {
+				attr, err := a.MarshalXMLAttr(a.Name)
+				if err != nil {
+					return nil, err
+				}
+
+				attrs = append(attrs, attr)
+			}
+
+			continue
+		}
+
+		omit := field.omitempty
+
+		if fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				continue
+			}
+
+			fv, omit = fv.Elem(), false
+		}
+
+		name := xml.Name{Local: field.name}
+
+		if m, ok := fv.Addr().Interface().(xml.MarshalerAttr); ok {
+			attr, err := m.MarshalXMLAttr(name)
+			if err != nil {
+				return nil, err
+			}
+
+			if attr.Name.Local != "" {
+				attrs = append(attrs, attr)
+			}
+
+			continue
+		}
+
+		if omit && fv.IsZero() {
+			continue
+		}
+
+		attrs = append(attrs, xml.Attr{Name: name, Value: fv.String()})
+	}
+
+	return attrs, nil
+}
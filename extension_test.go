@@ -0,0 +1,166 @@
+package xmltv
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const extendedDocument = `<tv xmlns:v="urn:vendor" generator-info-name="g" tvg-shift="2">` +
+	`<channel id="one" tvg-id="One.uk"><display-name>One</display-name><v:logo kind="k">one.png</v:logo></channel>` +
+	`<programme start="20220331180000 +0000" channel="one" catchup-id="c1"><title>News</title>` +
+	`<extra><id>9</id></extra></programme>` +
+	`<vendor-block a="b"></vendor-block></tv>`
+
+func TestExtensions(t *testing.T) {
+	t.Parallel()
+
+	var tv TV
+	if err := xml.Unmarshal([]byte(extendedDocument), &tv); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Attr{
+		{Name: xml.Name{Space: "xmlns", Local: "v"}, Value: "urn:vendor"},
+		{Name: xml.Name{Local: "tvg-shift"}, Value: "2"},
+	}
+	if diff := cmp.Diff(want, tv.Attrs); diff != "" {
+		t.Error(diff)
+	}
+
+	wantExtensions := []Extension{{
+		XMLName:  xml.Name{Space: "urn:vendor", Local: "logo"},
+		Attrs:    []Attr{{Name: xml.Name{Local: "kind"}, Value: "k"}},
+		InnerXML: "one.png",
+		Position: makePointer(1),
+	}}
+	if diff := cmp.Diff(wantExtensions, tv.Channels[0].Extensions); diff != "" {
+		t.Error(diff)
+	}
+
+	tv.Programmes[0].Titles[0].Text = "Late News"
+
+	got, err := xml.Marshal(&tv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantXML := `<tv generator-info-name="g" xmlns:v="urn:vendor" tvg-shift="2">` +
+		`<channel id="one" tvg-id="One.uk"><display-name>One</display-name><logo xmlns="urn:vendor" kind="k">one.png</logo></channel>` +
+		`<programme start="20220331180000 +0000" channel="one" catchup-id="c1"><title>Late News</title>` +
+		`<extra><id>9</id></extra></programme>` +
+		`<vendor-block a="b"></vendor-block></tv>`
+	if diff := cmp.Diff(wantXML, string(got)); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestExtensionsStreaming(t *testing.T) {
+	t.Parallel()
+
+	var want TV
+	if err := xml.Unmarshal([]byte(extendedDocument), &want); err != nil {
+		t.Fatal(err)
+	}
+
+	var got TV
+	if err := NewDecoder(bytes.NewReader([]byte(extendedDocument))).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatal(diff)
+	}
+
+	var buf bytes.Buffer
+
+	enc := NewEncoder(&buf)
+	if err := enc.WriteHeader(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range got.Programmes {
+		if err := enc.WriteProgramme(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	wantXML := xml.Header + `<tv generator-info-name="g" xmlns:v="urn:vendor" tvg-shift="2">` +
+		`<programme start="20220331180000 +0000" channel="one" catchup-id="c1"><title>News</title>` +
+		`<extra><id>9</id></extra></programme>` +
+		`<vendor-block a="b"></vendor-block></tv>` + "\n"
+	if diff := cmp.Diff(wantXML, buf.String()); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestExtensionsPosition(t *testing.T) {
+	t.Parallel()
+
+	doc := `<tv><channel id="one"><display-name>One</display-name></channel>` +
+		`<vendor-block></vendor-block>` +
+		`<programme start="20220331180000 +0000" channel="one"><title>News</title>` +
+		`<extra>9</extra><desc>Headlines</desc><foo:x xmlns:foo="urn:y">1</foo:x></programme></tv>`
+
+	// The namespace prefix is not kept.
+	want := `<tv><channel id="one"><display-name>One</display-name></channel>` +
+		`<vendor-block></vendor-block>` +
+		`<programme start="20220331180000 +0000" channel="one"><title>News</title>` +
+		`<extra>9</extra><desc>Headlines</desc><x xmlns="urn:y" xmlns:foo="urn:y">1</x></programme></tv>`
+
+	var tv TV
+	if err := xml.Unmarshal([]byte(doc), &tv); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := xml.Marshal(&tv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Error(diff)
+	}
+
+	var streamed TV
+	if err := NewDecoder(bytes.NewReader([]byte(doc))).Decode(&streamed); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(tv, streamed); diff != "" {
+		t.Error(diff)
+	}
+
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(&streamed); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(xml.Header+want+"\n", buf.String()); diff != "" {
+		t.Error(diff)
+	}
+
+	// Extensions without a position, or past the last sibling, are written last
+	// in the order they are held.
+	tv.Extensions[0].Position = nil
+	tv.Programmes[0].Extensions[0].Position = makePointer(5)
+
+	got, err = xml.Marshal(&tv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want = `<tv><channel id="one"><display-name>One</display-name></channel>` +
+		`<programme start="20220331180000 +0000" channel="one"><title>News</title>` +
+		`<desc>Headlines</desc><extra>9</extra><x xmlns="urn:y" xmlns:foo="urn:y">1</x></programme>` +
+		`<vendor-block></vendor-block></tv>`
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,148 @@
+package xmltv
+
+import (
+	"slices"
+	"time"
+)
+
+// FillerKeyword is the reserved keyword that tags the placeholder programmes
+// inserted by TV.FillGaps.
+const FillerKeyword = "xmltv:filler"
+
+// DefaultFillerTitle is the title of placeholder programmes when
+// FillOptions.Title is empty.
+const DefaultFillerTitle = "No Information"
+
+// FillOptions configures TV.FillGaps.
+type FillOptions struct {
+	// Title is the title of the placeholder programmes. When empty,
+	// DefaultFillerTitle is used.
+	Title string
+	// Lang is the optional language of Title.
+	Lang *string
+	// MinGap is the shortest gap that is filled. When zero, every gap is filled.
+	MinGap time.Duration
+	// From and To, when non-zero, pad every channel's schedule so that it starts
+	// no later than From and stops no earlier than To. A schedule whose last
+	// programme has no stop time is not padded to To. Channels without any
+	// programmes are filled from From to To when both are set.
+	From, To time.Time
+}
+
+// FillGaps inserts a placeholder programme into every gap of at least
+// opts.MinGap in each channel's schedule. Placeholders are tagged with the
+// FillerKeyword keyword, so that RemoveFillers can remove them later. Each
+// placeholder is inserted next to the programme preceding it, or following it
+// when padding the start of a schedule. Programmes without a stop time are taken
+// to stop when the next programme on the same channel starts. It returns the
+// number of placeholders inserted.
+func (tv *TV) FillGaps(opts FillOptions) int {
+	starts := newStartIndex(tv.Programmes)
+
+	var (
+		after    = make(map[int][]Programme)
+		before   = make(map[int][]Programme)
+		trailing []Programme
+		n        int
+	)
+
+	fill := func(channel string, start, stop time.Time) (Programme, bool) {
+		if !stop.After(start) || stop.Sub(start) < opts.MinGap {
+			return Programme{}, false
+		}
+
+		n++
+
+		return newFiller(channel, start, stop, opts), true
+	}
+
+	for _, channel := range tv.scheduleChannels() {
+		order := channel.programmes
+
+		if len(order) == 0 {
+			if !opts.From.IsZero() && !opts.To.IsZero() {
+				if p, ok := fill(channel.id, opts.From, opts.To); ok {
+					trailing = append(trailing, p)
+				}
+			}
+
+			continue
+		}
+
+		if first := order[0]; !opts.From.IsZero() {
+			if p, ok := fill(channel.id, opts.From, tv.Programmes[first].Start.Time); ok {
+				before[first] = append(before[first], p)
+			}
+		}
+
+		for _, gap := range channelGaps(tv.Programmes, order, starts) {
+			if p, ok := fill(channel.id, gap.Start, gap.Stop); ok {
+				after[gap.After] = append(after[gap.After], p)
+			}
+		}
+
+		// A schedule ending in a programme without a stop time is not padded, as
+		// the filler would start while that programme may still be on air.
+		if !opts.To.IsZero() && tv.Programmes[order[len(order)-1]].Stop != nil {
+			last, lastStop := order[0], starts.stop(&tv.Programmes[order[0]])
+			for _, i := range order[1:] {
+				if stop := starts.stop(&tv.Programmes[i]); stop.After(lastStop) {
+					last, lastStop = i, stop
+				}
+			}
+
+			if p, ok := fill(channel.id, lastStop, opts.To); ok {
+				after[last] = append(after[last], p)
+			}
+		}
+	}
+
+	if n == 0 {
+		return 0
+	}
+
+	programmes := make([]Programme, 0, len(tv.Programmes)+n)
+	for i, p := range tv.Programmes {
+		programmes = append(programmes, before[i]...)
+		programmes = append(programmes, p)
+		programmes = append(programmes, after[i]...)
+	}
+
+	tv.Programmes = append(programmes, trailing...)
+
+	return n
+}
+
+// RemoveFillers removes the placeholder programmes inserted by FillGaps and
+// returns the number removed.
+func (tv *TV) RemoveFillers() int {
+	n := len(tv.Programmes)
+	tv.Programmes = slices.DeleteFunc(tv.Programmes, func(p Programme) bool {
+		return p.IsFiller()
+	})
+
+	return n - len(tv.Programmes)
+}
+
+// IsFiller reports whether p is a placeholder inserted by TV.FillGaps.
+func (p *Programme) IsFiller() bool {
+	return slices.ContainsFunc(p.Keywords, func(k Keyword) bool {
+		return k.Text == FillerKeyword
+	})
+}
+
+// newFiller returns a placeholder programme on channel from start to stop.
+func newFiller(channel string, start, stop time.Time, opts FillOptions) Programme {
+	title := opts.Title
+	if title == "" {
+		title = DefaultFillerTitle
+	}
+
+	return Programme{
+		Start:    Time{Time: start},
+		Stop:     &Time{Time: stop},
+		Channel:  channel,
+		Titles:   []Title{{Lang: opts.Lang, Text: title}},
+		Keywords: []Keyword{{Text: FillerKeyword}},
+	}
+}
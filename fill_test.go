@@ -0,0 +1,75 @@
+package xmltv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFillGaps(t *testing.T) {
+	t.Parallel()
+
+	tv := TV{
+		Channels: []Channel{{ID: "one"}, {ID: "two"}, {ID: "three"}},
+		Programmes: []Programme{
+			newProgramme(t, "one", "202203311900", "202203312000", "Film"),
+			newProgramme(t, "one", "202203312005", "202203312100", "Short gap"),
+			newProgramme(t, "two", "202203311800", "202203311900", "News"),
+			newProgramme(t, "one", "202203312200", "", "Late"),
+		},
+	}
+
+	n := tv.FillGaps(FillOptions{
+		Title:  "Pas d'information",
+		Lang:   makePointer("fr"),
+		MinGap: 10 * time.Minute,
+		From:   parseTime(t, "200601021504", "202203311800"),
+		To:     parseTime(t, "200601021504", "202203312300"),
+	})
+
+	got := make([]string, len(tv.Programmes))
+	for i, p := range tv.Programmes {
+		stop := "open"
+		if p.Stop != nil {
+			stop = p.Stop.Format("1504")
+		}
+
+		got[i] = p.Channel + " " + p.Titles[0].Text + " " + p.Start.Format("1504") + "-" + stop
+	}
+
+	want := []string{
+		"one Pas d'information 1800-1900",
+		"one Film 1900-2000",
+		"one Short gap 2005-2100",
+		"one Pas d'information 2100-2200",
+		"two News 1800-1900",
+		"two Pas d'information 1900-2300",
+		"one Late 2200-open",
+		"three Pas d'information 1800-2300",
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatal(diff)
+	}
+
+	if diff := cmp.Diff(4, n); diff != "" {
+		t.Fatal(diff)
+	}
+
+	if !tv.Analyze(AnalyzeOptions{MinGap: 10 * time.Minute}).OK() {
+		t.Fatal("FillGaps introduced an overlap")
+	}
+
+	if !tv.Programmes[0].IsFiller() || tv.Programmes[1].IsFiller() {
+		t.Fatal("IsFiller does not identify placeholders")
+	}
+
+	if diff := cmp.Diff(4, tv.RemoveFillers()); diff != "" {
+		t.Fatal(diff)
+	}
+
+	if diff := cmp.Diff([]string{"Film", "Short gap", "News", "Late"}, programmeTitles(tv.Programmes)); diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -2,4 +2,7 @@ module github.com/sherif-fanous/xmltv
 
 go 1.24.0
 
-require github.com/google/go-cmp v0.7.0
+require (
+	github.com/google/go-cmp v0.7.0
+	golang.org/x/text v0.34.0
+)
@@ -0,0 +1,171 @@
+package xmltv
+
+import (
+	"slices"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// Index answers schedule lookups, such as what is on a channel at a given time,
+// over a snapshot of a TV. It is safe for concurrent use; Rebuild swaps in a new
+// snapshot atomically without blocking readers.
+//
+// Lookups assume that each channel's programmes do not overlap. Programmes
+// without a stop time are taken to stop when the next programme on the same
+// channel starts.
+type Index struct {
+	snapshot atomic.Pointer[indexSnapshot]
+}
+
+type indexSnapshot struct {
+	channels   map[string]*channelSchedule
+	channelIDs []string
+}
+
+// channelSchedule is the programmes of a channel sorted by start time, with
+// their effective stop times.
+type channelSchedule struct {
+	programmes []Programme
+	stops      []time.Time
+}
+
+// NewIndex returns an index over the programmes of tv.
+func NewIndex(tv *TV) *Index {
+	idx := &Index{}
+	idx.Rebuild(tv)
+
+	return idx
+}
+
+// Rebuild replaces the indexed programmes with those of tv. Lookups running
+// concurrently see either the old or the new programmes, never a mix. The
+// programmes are copied shallowly, so tv's slices may be reused afterwards but
+// the values they point to must not be modified while indexed.
+func (idx *Index) Rebuild(tv *TV) {
+	s := &indexSnapshot{channels: make(map[string]*channelSchedule)}
+
+	for _, c := range tv.Channels {
+		s.channelIDs = append(s.channelIDs, c.ID)
+		s.channels[c.ID] = &channelSchedule{}
+	}
+
+	for _, p := range tv.Programmes {
+		schedule, ok := s.channels[p.Channel]
+		if !ok {
+			s.channelIDs = append(s.channelIDs, p.Channel)
+			schedule = &channelSchedule{}
+			s.channels[p.Channel] = schedule
+		}
+
+		schedule.programmes = append(schedule.programmes, p)
+	}
+
+	starts := newStartIndex(tv.Programmes)
+
+	for _, schedule := range s.channels {
+		slices.SortStableFunc(schedule.programmes, func(a, b Programme) int {
+			return a.Start.Compare(b.Start.Time)
+		})
+
+		schedule.stops = make([]time.Time, len(schedule.programmes))
+		for i := range schedule.programmes {
+			schedule.stops[i] = starts.stop(&schedule.programmes[i])
+		}
+	}
+
+	idx.snapshot.Store(s)
+}
+
+// At returns the programme on air on channelID at t, or nil if there is none.
+func (idx *Index) At(channelID string, t time.Time) *Programme {
+	now, _ := idx.NowNext(channelID, t)
+
+	return now
+}
+
+// NowNext returns the programme on air on channelID at t, if any, and the first
+// programme starting after t, if any.
+func (idx *Index) NowNext(channelID string, t time.Time) (now, next *Programme) {
+	schedule := idx.snapshot.Load().channels[channelID]
+	if schedule == nil {
+		return nil, nil
+	}
+
+	if i := schedule.at(t); i >= 0 {
+		p := schedule.programmes[i]
+		now = &p
+	}
+
+	if i := schedule.startsAfter(t); i < len(schedule.programmes) {
+		p := schedule.programmes[i]
+		next = &p
+	}
+
+	return now, next
+}
+
+// Between returns the programmes on channelID that are on air at any time in the
+// half-open window [from, to), in start order.
+func (idx *Index) Between(channelID string, from, to time.Time) []Programme {
+	schedule := idx.snapshot.Load().channels[channelID]
+	if schedule == nil {
+		return nil
+	}
+
+	lo := sort.Search(len(schedule.stops), func(i int) bool {
+		return schedule.stops[i].After(from) || !schedule.programmes[i].Start.Before(from)
+	})
+	hi := sort.Search(len(schedule.programmes), func(i int) bool {
+		return !schedule.programmes[i].Start.Before(to)
+	})
+
+	if lo >= hi {
+		return nil
+	}
+
+	return slices.Clone(schedule.programmes[lo:hi])
+}
+
+// ChannelsAt returns the programme on air at t on every channel that has one,
+// keyed by channel ID.
+func (idx *Index) ChannelsAt(t time.Time) map[string]Programme {
+	s := idx.snapshot.Load()
+
+	onAir := make(map[string]Programme)
+
+	for _, id := range s.channelIDs {
+		schedule := s.channels[id]
+		if i := schedule.at(t); i >= 0 {
+			onAir[id] = schedule.programmes[i]
+		}
+	}
+
+	return onAir
+}
+
+// at returns the index of the programme on air at t, or -1 if there is none.
+// Among programmes sharing a start time, such as a clump, the first is returned.
+func (s *channelSchedule) at(t time.Time) int {
+	i := s.startsAfter(t) - 1
+	if i < 0 {
+		return -1
+	}
+
+	for i > 0 && s.programmes[i-1].Start.Equal(s.programmes[i].Start.Time) {
+		i--
+	}
+
+	if !s.stops[i].After(t) {
+		return -1
+	}
+
+	return i
+}
+
+// startsAfter returns the index of the first programme starting after t.
+func (s *channelSchedule) startsAfter(t time.Time) int {
+	return sort.Search(len(s.programmes), func(i int) bool {
+		return s.programmes[i].Start.After(t)
+	})
+}
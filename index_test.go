@@ -0,0 +1,101 @@
+package xmltv
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIndex(t *testing.T) {
+	t.Parallel()
+
+	tv := TV{
+		Channels: []Channel{{ID: "one"}, {ID: "two"}},
+		Programmes: []Programme{
+			newProgramme(t, "one", "202203311900", "", "Evening"),
+			newProgramme(t, "one", "202203311800", "202203311900", "News"),
+			newProgramme(t, "one", "202203312100", "202203312200", "Late"),
+			newProgramme(t, "two", "202203311830", "202203312000", "Film"),
+		},
+	}
+
+	idx := NewIndex(&tv)
+
+	at := func(value string) string {
+		t.Helper()
+
+		p := idx.At("one", parseTime(t, "200601021504", value))
+		if p == nil {
+			return ""
+		}
+
+		return p.Titles[0].Text
+	}
+
+	for value, want := range map[string]string{
+		"202203311759": "",
+		"202203311800": "News",
+		"202203311859": "News",
+		"202203311900": "Evening",
+		"202203312130": "Late",
+		"202203312200": "",
+	} {
+		if got := at(value); got != want {
+			t.Errorf("At(%s): got %q, want %q", value, got, want)
+		}
+	}
+
+	now, next := idx.NowNext("one", parseTime(t, "200601021504", "202203311830"))
+	if now == nil || now.Titles[0].Text != "News" || next == nil || next.Titles[0].Text != "Evening" {
+		t.Fatalf("NowNext: got %v, %v", now, next)
+	}
+
+	between := idx.Between("one", parseTime(t, "200601021504", "202203311830"), parseTime(t, "200601021504", "202203312100"))
+	if diff := cmp.Diff([]string{"News", "Evening"}, programmeTitles(between)); diff != "" {
+		t.Fatal(diff)
+	}
+
+	onAir := idx.ChannelsAt(parseTime(t, "200601021504", "202203311845"))
+	if len(onAir) != 2 || onAir["one"].Titles[0].Text != "News" || onAir["two"].Titles[0].Text != "Film" {
+		t.Fatalf("ChannelsAt: got %v", onAir)
+	}
+}
+
+func TestIndexConcurrentRebuild(t *testing.T) {
+	t.Parallel()
+
+	first := TV{Programmes: []Programme{newProgramme(t, "one", "202203311800", "202203311900", "First")}}
+	second := TV{Programmes: []Programme{newProgramme(t, "one", "202203311800", "202203311900", "Second")}}
+	at := parseTime(t, "200601021504", "202203311830")
+
+	idx := NewIndex(&first)
+
+	var wg sync.WaitGroup
+
+	for range 8 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for range 1000 {
+				if p := idx.At("one", at); p == nil {
+					t.Error("At returned nil during Rebuild")
+
+					return
+				}
+			}
+		}()
+	}
+
+	for i := range 1000 {
+		if i%2 == 0 {
+			idx.Rebuild(&second)
+		} else {
+			idx.Rebuild(&first)
+		}
+	}
+
+	wg.Wait()
+}
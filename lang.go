@@ -0,0 +1,187 @@
+package xmltv
+
+import "strings"
+
+// Localized is implemented by every element holding text in an optional
+// language, such as Title, Description and DisplayName.
+type Localized interface {
+	// Locale returns the element's lang attribute, or "" if it has none.
+	Locale() string
+	// Content returns the element's text.
+	Content() string
+}
+
+func (d DisplayName) Locale() string       { return deref(d.Lang) }
+func (d DisplayName) Content() string      { return d.Text }
+func (t Title) Locale() string             { return deref(t.Lang) }
+func (t Title) Content() string            { return t.Text }
+func (t SubTitle) Locale() string          { return deref(t.Lang) }
+func (t SubTitle) Content() string         { return t.Text }
+func (d Description) Locale() string       { return deref(d.Lang) }
+func (d Description) Content() string      { return d.Text }
+func (c Category) Locale() string          { return deref(c.Lang) }
+func (c Category) Content() string         { return c.Text }
+func (k Keyword) Locale() string           { return deref(k.Lang) }
+func (k Keyword) Content() string          { return k.Text }
+func (l Language) Locale() string          { return deref(l.Lang) }
+func (l Language) Content() string         { return l.Text }
+func (l OriginalLanguage) Locale() string  { return deref(l.Lang) }
+func (l OriginalLanguage) Content() string { return l.Text }
+func (c Country) Locale() string           { return deref(c.Lang) }
+func (c Country) Content() string          { return c.Text }
+func (p Premiere) Locale() string          { return deref(p.Lang) }
+func (p Premiere) Content() string         { return p.Text }
+func (l LastChance) Locale() string        { return deref(l.Lang) }
+func (l LastChance) Content() string       { return l.Text }
+func (r Review) Locale() string            { return deref(r.Lang) }
+func (r Review) Content() string           { return r.Text }
+
+// Best returns the element of items that best suits a reader of the BCP 47
+// language tags prefs, in order of preference. For each preference in turn it
+// looks for an exact match, then for matches of ever shorter prefixes of the tag,
+// so that fr-CA falls back to fr, and then for any element in the same primary
+// language, so that fr also matches fr-FR. Failing all preferences it returns the
+// first element without a language, and failing that the first element. Tags are
+// compared case-insensitively. ok is false only when items is empty.
+func Best[T Localized](items []T, prefs ...string) (best T, ok bool) {
+	if len(items) == 0 {
+		return best, false
+	}
+
+	tags := make([]string, len(items))
+	for i, item := range items {
+		tags[i] = normalizeTag(item.Locale())
+	}
+
+	for _, pref := range prefs {
+		pref = normalizeTag(pref)
+		if pref == "" {
+			continue
+		}
+
+		for prefix := pref; prefix != ""; prefix = truncateTag(prefix) {
+			for i, tag := range tags {
+				if tag == prefix {
+					return items[i], true
+				}
+			}
+		}
+
+		primary, _, _ := strings.Cut(pref, "-")
+		for i, tag := range tags {
+			if tag != "" && strings.HasPrefix(tag, primary) && (len(tag) == len(primary) || tag[len(primary)] == '-') {
+				return items[i], true
+			}
+		}
+	}
+
+	for i, tag := range tags {
+		if tag == "" {
+			return items[i], true
+		}
+	}
+
+	return items[0], true
+}
+
+// BestDisplayName returns the display name that best suits prefs, as chosen by
+// Best, or the zero DisplayName if the channel has none.
+func (c *Channel) BestDisplayName(prefs ...string) DisplayName {
+	best, _ := Best(c.DisplayNames, prefs...)
+
+	return best
+}
+
+// BestTitle returns the title that best suits prefs, as chosen by Best, or the
+// zero Title if the programme has none.
+func (p *Programme) BestTitle(prefs ...string) Title {
+	best, _ := Best(p.Titles, prefs...)
+
+	return best
+}
+
+// BestSubTitle returns the sub-title that best suits prefs, as chosen by Best,
+// or the zero SubTitle if the programme has none.
+func (p *Programme) BestSubTitle(prefs ...string) SubTitle {
+	best, _ := Best(p.SubTitles, prefs...)
+
+	return best
+}
+
+// BestDescription returns the description that best suits prefs, as chosen by
+// Best, or the zero Description if the programme has none.
+func (p *Programme) BestDescription(prefs ...string) Description {
+	best, _ := Best(p.Descriptions, prefs...)
+
+	return best
+}
+
+// BestReview returns the review that best suits prefs, as chosen by Best, or
+// the zero Review if the programme has none.
+func (p *Programme) BestReview(prefs ...string) Review {
+	best, _ := Best(p.Reviews, prefs...)
+
+	return best
+}
+
+// BestCategories returns the categories in the language that best suits prefs,
+// as chosen by Best, keeping their order.
+func (p *Programme) BestCategories(prefs ...string) []Category {
+	return bestAll(p.Categories, prefs)
+}
+
+// BestKeywords returns the keywords in the language that best suits prefs, as
+// chosen by Best, keeping their order.
+func (p *Programme) BestKeywords(prefs ...string) []Keyword {
+	return bestAll(p.Keywords, prefs)
+}
+
+// bestAll returns the items sharing the language of the item chosen by Best.
+func bestAll[T Localized](items []T, prefs []string) []T {
+	best, ok := Best(items, prefs...)
+	if !ok {
+		return nil
+	}
+
+	tag := normalizeTag(best.Locale())
+
+	var all []T
+
+	for _, item := range items {
+		if normalizeTag(item.Locale()) == tag {
+			all = append(all, item)
+		}
+	}
+
+	return all
+}
+
+// normalizeTag lower-cases a language tag and replaces underscores, as found in
+// POSIX locale names, with hyphens.
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+}
+
+// truncateTag removes the last subtag of tag, along with a single-letter subtag
+// such as an extension singleton left before it, as in RFC 4647 lookup.
+func truncateTag(tag string) string {
+	i := strings.LastIndexByte(tag, '-')
+	if i < 0 {
+		return ""
+	}
+
+	tag = tag[:i]
+	if i = strings.LastIndexByte(tag, '-'); i >= 0 && len(tag)-i == 2 {
+		tag = tag[:i]
+	}
+
+	return tag
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
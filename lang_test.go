@@ -0,0 +1,87 @@
+package xmltv
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBest(t *testing.T) {
+	t.Parallel()
+
+	titles := []Title{
+		{Lang: makePointer("en"), Text: "News"},
+		{Text: "Untagged"},
+		{Lang: makePointer("fr-FR"), Text: "Informations"},
+		{Lang: makePointer("FR"), Text: "Journal"},
+		{Lang: makePointer("pt-BR"), Text: "Notícias"},
+	}
+
+	tests := []struct {
+		name  string
+		prefs []string
+		want  string
+	}{
+		{name: "exact", prefs: []string{"fr-FR"}, want: "Informations"},
+		{name: "truncated", prefs: []string{"fr-CA"}, want: "Journal"},
+		{name: "case and underscore", prefs: []string{"FR_fr"}, want: "Informations"},
+		{name: "same primary language", prefs: []string{"pt"}, want: "Notícias"},
+		{name: "second preference", prefs: []string{"de", "en-GB"}, want: "News"},
+		{name: "untagged", prefs: []string{"de"}, want: "Untagged"},
+		{name: "no preferences", want: "Untagged"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := Best(titles, tt.prefs...)
+			if !ok || got.Text != tt.want {
+				t.Fatalf("got %q, %t; want %q", got.Text, ok, tt.want)
+			}
+		})
+	}
+
+	if got, _ := Best(titles[2:], "de"); got.Text != "Informations" {
+		t.Fatalf("got %q without an untagged title, want the first", got.Text)
+	}
+
+	if _, ok := Best([]Title(nil), "en"); ok {
+		t.Fatal("got ok for no titles")
+	}
+}
+
+func TestProgrammeBest(t *testing.T) {
+	t.Parallel()
+
+	p := Programme{
+		Titles:       []Title{{Lang: makePointer("en"), Text: "Film"}, {Lang: makePointer("fr"), Text: "Le film"}},
+		Descriptions: []Description{{Lang: makePointer("fr-CA"), Text: "Un film"}},
+		Categories: []Category{
+			{Lang: makePointer("en"), Text: "Movie"},
+			{Lang: makePointer("fr"), Text: "Film"},
+			{Lang: makePointer("en"), Text: "Drama"},
+		},
+	}
+
+	if got := p.BestTitle("fr-CA", "en"); got.Text != "Le film" {
+		t.Errorf("BestTitle = %q, want %q", got.Text, "Le film")
+	}
+
+	if got := p.BestDescription("fr"); got.Text != "Un film" {
+		t.Errorf("BestDescription = %q, want %q", got.Text, "Un film")
+	}
+
+	if got := p.BestSubTitle("fr"); got != (SubTitle{}) {
+		t.Errorf("BestSubTitle = %+v, want the zero SubTitle", got)
+	}
+
+	if diff := cmp.Diff([]Category{p.Categories[0], p.Categories[2]}, p.BestCategories("en-US")); diff != "" {
+		t.Error(diff)
+	}
+
+	c := Channel{DisplayNames: []DisplayName{{Text: "One"}, {Lang: makePointer("fr"), Text: "Un"}}}
+	if got := c.BestDisplayName("en"); got.Text != "One" {
+		t.Errorf("BestDisplayName = %q, want %q", got.Text, "One")
+	}
+}
@@ -0,0 +1,69 @@
+package xmltv
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNoDuration is returned by Programme.Duration for a programme with neither a
+// stop time nor a length.
+var ErrNoDuration = errors.New("xmltv: programme has no stop time or length")
+
+// NewLength returns the Length of d in the largest unit that represents it
+// exactly. d is rounded to the nearest second, the smallest unit the DTD allows.
+func NewLength(d time.Duration) Length {
+	d = d.Round(time.Second)
+
+	units, unit := LengthUnitsSeconds, time.Second
+
+	switch {
+	case d == 0:
+	case d%time.Hour == 0:
+		units, unit = LengthUnitsHours, time.Hour
+	case d%time.Minute == 0:
+		units, unit = LengthUnitsMinutes, time.Minute
+	}
+
+	value := int(d / unit)
+
+	return Length{Units: units, Text: &value}
+}
+
+// Duration returns the duration l represents. It returns an error if l has no
+// value or its Units are not one of the LengthUnits constants.
+func (l Length) Duration() (time.Duration, error) {
+	if l.Text == nil {
+		return 0, errors.New("xmltv: length has no value")
+	}
+
+	var unit time.Duration
+
+	switch l.Units {
+	case LengthUnitsSeconds:
+		unit = time.Second
+	case LengthUnitsMinutes:
+		unit = time.Minute
+	case LengthUnitsHours:
+		unit = time.Hour
+	default:
+		return 0, fmt.Errorf("xmltv: unknown length units %q", l.Units)
+	}
+
+	return time.Duration(*l.Text) * unit, nil
+}
+
+// Duration returns how long p runs: the time from Start to Stop if p has a stop
+// time, and otherwise the duration of its Length. It returns ErrNoDuration if p
+// has neither.
+func (p *Programme) Duration() (time.Duration, error) {
+	if p.Stop != nil && !p.Stop.IsZero() {
+		return p.Stop.Sub(p.Start.Time), nil
+	}
+
+	if p.Length == nil {
+		return 0, ErrNoDuration
+	}
+
+	return p.Length.Duration()
+}
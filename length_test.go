@@ -0,0 +1,85 @@
+package xmltv
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLengthDuration(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		length Length
+		want   time.Duration
+	}{
+		{length: Length{Units: LengthUnitsSeconds, Text: makePointer(90)}, want: 90 * time.Second},
+		{length: Length{Units: LengthUnitsMinutes, Text: makePointer(45)}, want: 45 * time.Minute},
+		{length: Length{Units: LengthUnitsHours, Text: makePointer(2)}, want: 2 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.length.Duration()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got != tt.want {
+			t.Fatalf("%d %s: got %v, want %v", *tt.length.Text, tt.length.Units, got, tt.want)
+		}
+	}
+
+	if _, err := (Length{Units: "days", Text: makePointer(1)}).Duration(); err == nil {
+		t.Fatal("expected error for unknown units, got nil")
+	}
+
+	if _, err := (Length{Units: LengthUnitsMinutes}).Duration(); err == nil {
+		t.Fatal("expected error for missing value, got nil")
+	}
+}
+
+func TestNewLength(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		d    time.Duration
+		want Length
+	}{
+		{d: 2 * time.Hour, want: Length{Units: LengthUnitsHours, Text: makePointer(2)}},
+		{d: 90 * time.Minute, want: Length{Units: LengthUnitsMinutes, Text: makePointer(90)}},
+		{d: 61 * time.Second, want: Length{Units: LengthUnitsSeconds, Text: makePointer(61)}},
+		{d: 1500 * time.Millisecond, want: Length{Units: LengthUnitsSeconds, Text: makePointer(2)}},
+		{d: 0, want: Length{Units: LengthUnitsSeconds, Text: makePointer(0)}},
+	}
+
+	for _, tt := range tests {
+		if diff := cmp.Diff(tt.want, NewLength(tt.d)); diff != "" {
+			t.Fatalf("%v: %s", tt.d, diff)
+		}
+	}
+}
+
+func TestProgrammeDuration(t *testing.T) {
+	t.Parallel()
+
+	p := newProgramme(t, "one", "202203311800", "202203311930", "Film")
+	p.Length = &Length{Units: LengthUnitsMinutes, Text: makePointer(85)}
+
+	if got, err := p.Duration(); err != nil || got != 90*time.Minute {
+		t.Fatalf("got %v, %v, want 1h30m0s", got, err)
+	}
+
+	p.Stop = nil
+
+	if got, err := p.Duration(); err != nil || got != 85*time.Minute {
+		t.Fatalf("got %v, %v, want 1h25m0s", got, err)
+	}
+
+	p.Length = nil
+
+	if _, err := p.Duration(); !errors.Is(err, ErrNoDuration) {
+		t.Fatalf("got %v, want ErrNoDuration", err)
+	}
+}
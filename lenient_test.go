@@ -0,0 +1,97 @@
+package xmltv
+
+import (
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const damagedDocument = `<?xml version="1.0"?>
+<tv>
+  <channel id="one"><display-name>One</display-name></channel>
+  <channel id="two"><icon src="two.png" width="wide"/></channel>
+  <programme start="20220331180000 +0000" channel="one"><title>News</title></programme>
+  <programme start="yesterday" channel="one"><title>Broken</title></programme>
+  <programme start="20220331190000 +0000" channel="one"><title>Film</title></programme>
+</tv>
+`
+
+func TestDecoderLenient(t *testing.T) {
+	t.Parallel()
+
+	d := NewDecoder(strings.NewReader(damagedDocument))
+	d.SetMode(DecodeModeLenient)
+
+	var tv TV
+	err := d.Decode(&tv)
+
+	var errs DecodeErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("got error %v, want DecodeErrors", err)
+	}
+
+	got := make([]string, len(errs))
+	for i, e := range errs {
+		got[i] = e.Path
+	}
+
+	if diff := cmp.Diff([]string{"/tv/channel[2]", "/tv/programme[2]"}, got); diff != "" {
+		t.Error(diff)
+	}
+
+	if e := errs[1]; e.Line != 6 || e.Column != 3 || e.Offset != int64(strings.Index(damagedDocument, `<programme start="yesterday"`)) {
+		t.Errorf("got position %d:%d, offset %d", e.Line, e.Column, e.Offset)
+	}
+
+	if len(tv.Channels) != 1 || tv.Channels[0].ID != "one" {
+		t.Errorf("got channels %+v, want only one", tv.Channels)
+	}
+
+	if diff := cmp.Diff([]string{"News", "Film"}, programmeTitles(tv.Programmes)); diff != "" {
+		t.Error(diff)
+	}
+
+	if err := NewDecoder(strings.NewReader(damagedDocument)).Decode(&TV{}); err == nil {
+		t.Error("default mode: got no error")
+	}
+}
+
+func TestDecoderLenientProgrammes(t *testing.T) {
+	t.Parallel()
+
+	d := NewDecoder(strings.NewReader(damagedDocument))
+	d.SetMode(DecodeModeLenient)
+
+	var titles []string
+
+	for p, err := range d.Programmes() {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		titles = append(titles, p.Titles[0].Text)
+	}
+
+	if diff := cmp.Diff([]string{"News", "Film"}, titles); diff != "" {
+		t.Error(diff)
+	}
+
+	if len(d.Errors()) != 1 {
+		t.Errorf("got errors %v, want one for the broken programme", d.Errors())
+	}
+}
+
+func TestDecoderLenientMalformed(t *testing.T) {
+	t.Parallel()
+
+	d := NewDecoder(strings.NewReader(`<tv><programme start="20220331180000 +0000" channel="one"><title>News</programme></tv>`))
+	d.SetMode(DecodeModeLenient)
+
+	var syntaxErr *xml.SyntaxError
+	if err := d.Decode(&TV{}); !errors.As(err, &syntaxErr) {
+		t.Fatalf("got error %v, want an *xml.SyntaxError", err)
+	}
+}
@@ -0,0 +1,317 @@
+package xmltv
+
+import (
+	"cmp"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// MergePolicy decides which of two overlapping programmes from different sources
+// is kept by Merge.
+//
+// Overlaps are resolved programme by programme, not slot by slot: a kept
+// programme removes every programme from another source that overlaps it even
+// in part. One long programme can therefore displace several shorter ones and
+// leave a gap where they did not overlap it. For example, a 18:30-19:30
+// programme chosen over an 18:00-19:00 and a 19:00-20:00 one leaves 18:00-18:30
+// and 19:30-20:00 empty.
+type MergePolicy int
+
+const (
+	// MergePolicyPriority keeps the programme from the source passed first.
+	MergePolicyPriority MergePolicy = iota
+	// MergePolicyLongestDescription keeps the programme with the longest
+	// description.
+	MergePolicyLongestDescription
+	// MergePolicyMostPopulated keeps the programme with the most populated
+	// fields.
+	MergePolicyMostPopulated
+)
+
+// MergeOptions configures Merge.
+type MergeOptions struct {
+	Policy MergePolicy
+}
+
+// MergeSlot records which source supplied a programme of a merged document.
+type MergeSlot struct {
+	Channel string     `json:"channel"`
+	Start   time.Time  `json:"start"`
+	Stop    *time.Time `json:"stop,omitempty"`
+	// Source is the index of the winning document in the arguments to Merge.
+	Source int `json:"source"`
+	// Overridden lists the indexes of the documents whose overlapping programmes
+	// lost to this one.
+	Overridden []int `json:"overridden,omitempty"`
+}
+
+// mergeCandidate is a programme together with the index of its source document.
+type mergeCandidate struct {
+	programme *Programme
+	source    int
+	slot      int
+}
+
+// Merge combines docs into a single document. Channels are unioned by ID, with
+// their display names, icons, URLs and extensions merged without duplicates.
+// Programmes are combined per channel; when programmes from different sources
+// overlap, the policy in opts decides which one is kept, and ties go to the
+// source passed first. Overlapping programmes from the same source, such as
+// clumps, are all kept. Root attributes are taken from the first document that
+// sets them, and root extensions are merged without duplicates.
+//
+// The merged programmes are ordered by channel, in order of first appearance,
+// then by start time. The returned slots are parallel to the merged programmes
+// and report the source that supplied each one. Programmes are copied shallowly,
+// so the merged document shares their nested values with docs.
+func Merge(opts MergeOptions, docs ...*TV) (*TV, []MergeSlot) {
+	merged := &TV{}
+
+	channelIndex := make(map[string]int)
+	channelOrder := make(map[string]int)
+
+	var candidates []mergeCandidate
+
+	for source, doc := range docs {
+		if doc == nil {
+			continue
+		}
+
+		mergeRoot(merged, doc)
+
+		for _, c := range doc.Channels {
+			if i, ok := channelIndex[c.ID]; ok {
+				mergeChannel(&merged.Channels[i], &c)
+
+				continue
+			}
+
+			c.DisplayNames = slices.Clone(c.DisplayNames)
+			c.Icons = slices.Clone(c.Icons)
+			c.URLs = slices.Clone(c.URLs)
+
+			channelIndex[c.ID] = len(merged.Channels)
+			merged.Channels = append(merged.Channels, c)
+		}
+
+		for i := range doc.Programmes {
+			p := &doc.Programmes[i]
+			if _, ok := channelOrder[p.Channel]; !ok {
+				channelOrder[p.Channel] = len(channelOrder)
+			}
+
+			candidates = append(candidates, mergeCandidate{programme: p, source: source})
+		}
+	}
+
+	// Visit the candidates best first, so that a programme is kept unless it
+	// overlaps a better one from another source.
+	rank := mergeRank(opts.Policy)
+	slices.SortStableFunc(candidates, func(a, b mergeCandidate) int {
+		return cmp.Or(cmp.Compare(rank(b.programme), rank(a.programme)), cmp.Compare(a.source, b.source))
+	})
+
+	kept := make(map[string]*keptProgrammes)
+
+	var slots []MergeSlot
+
+	for _, c := range candidates {
+		k := kept[c.programme.Channel]
+		if k == nil {
+			k = &keptProgrammes{}
+			kept[c.programme.Channel] = k
+		}
+
+		if winner := k.winner(c); winner >= 0 {
+			if !slices.Contains(slots[winner].Overridden, c.source) {
+				slots[winner].Overridden = append(slots[winner].Overridden, c.source)
+			}
+
+			continue
+		}
+
+		c.slot = len(slots)
+		k.add(c)
+
+		slot := MergeSlot{Channel: c.programme.Channel, Start: c.programme.Start.Time, Source: c.source}
+		if c.programme.Stop != nil {
+			stop := c.programme.Stop.Time
+			slot.Stop = &stop
+		}
+
+		slots = append(slots, slot)
+	}
+
+	var winners []mergeCandidate
+	for _, k := range kept {
+		winners = append(winners, k.byStart...)
+	}
+
+	slices.SortFunc(winners, func(a, b mergeCandidate) int {
+		return cmp.Or(
+			cmp.Compare(channelOrder[a.programme.Channel], channelOrder[b.programme.Channel]),
+			a.programme.Start.Compare(b.programme.Start.Time),
+			cmp.Compare(a.slot, b.slot),
+		)
+	})
+
+	orderedSlots := make([]MergeSlot, len(winners))
+	for i, w := range winners {
+		merged.Programmes = append(merged.Programmes, *w.programme)
+		orderedSlots[i] = slots[w.slot]
+	}
+
+	return merged, orderedSlots
+}
+
+// keptProgrammes holds the programmes Merge has kept on a channel, sorted by
+// start time, and the longest time any of them occupies, which bounds how long
+// before a programme one that overlaps it can start.
+type keptProgrammes struct {
+	byStart []mergeCandidate
+	longest time.Duration
+}
+
+// winner returns the slot of the first kept programme from another source than
+// c that overlaps it, or -1 if there is none.
+func (k *keptProgrammes) winner(c mergeCandidate) int {
+	p := c.programme
+
+	// Only the programmes starting before p ends, and less than longest before
+	// it starts, can overlap it.
+	i := k.search(end(p))
+	winner := -1
+
+	for _, q := range slices.Backward(k.byStart[:i]) {
+		if !q.programme.Start.Add(k.longest).After(p.Start.Time) {
+			break
+		}
+
+		if q.source != c.source && overlaps(q.programme, p) && (winner < 0 || q.slot < winner) {
+			winner = q.slot
+		}
+	}
+
+	return winner
+}
+
+// add keeps c.
+func (k *keptProgrammes) add(c mergeCandidate) {
+	k.byStart = slices.Insert(k.byStart, k.search(c.programme.Start.Time), c)
+	k.longest = max(k.longest, end(c.programme).Sub(c.programme.Start.Time))
+}
+
+// search returns the index of the first kept programme starting at or after t.
+func (k *keptProgrammes) search(t time.Time) int {
+	i, _ := slices.BinarySearchFunc(k.byStart, t, func(c mergeCandidate, t time.Time) int {
+		return c.programme.Start.Compare(t)
+	})
+
+	return i
+}
+
+// mergeRoot copies the root attributes of doc that merged does not set yet and
+// appends its missing extensions.
+func mergeRoot(merged, doc *TV) {
+	if merged.Date == nil {
+		merged.Date = doc.Date
+	}
+
+	for _, attr := range []struct{ dst, src **string }{
+		{&merged.SourceInfoURL, &doc.SourceInfoURL},
+		{&merged.SourceInfoName, &doc.SourceInfoName},
+		{&merged.SourceDataURL, &doc.SourceDataURL},
+		{&merged.GeneratorInfoName, &doc.GeneratorInfoName},
+		{&merged.GeneratorInfoURL, &doc.GeneratorInfoURL},
+	} {
+		if *attr.dst == nil {
+			*attr.dst = *attr.src
+		}
+	}
+
+	merged.Attrs, merged.Extensions = mergeExtensions(merged.Attrs, merged.Extensions, doc.Attrs, doc.Extensions)
+}
+
+// mergeChannel appends the display names, icons, URLs, attributes and extensions
+// of c missing from dst.
+func mergeChannel(dst, c *Channel) {
+	dst.DisplayNames = appendMissing(dst.DisplayNames, c.DisplayNames, func(a, b DisplayName) bool {
+		return a.Text == b.Text && equalPtr(a.Lang, b.Lang)
+	})
+	dst.Icons = appendMissing(dst.Icons, c.Icons, func(a, b Icon) bool {
+		return a.Source == b.Source
+	})
+	dst.URLs = appendMissing(dst.URLs, c.URLs, func(a, b URL) bool {
+		return a.Text == b.Text && equalPtr(a.System, b.System)
+	})
+	dst.Attrs, dst.Extensions = mergeExtensions(dst.Attrs, dst.Extensions, c.Attrs, c.Extensions)
+}
+
+// mergeExtensions appends the attributes not yet set and the extension elements
+// missing from the first pair to it.
+func mergeExtensions(attrs []Attr, exts []Extension, srcAttrs []Attr, srcExts []Extension) ([]Attr, []Extension) {
+	attrs = appendMissing(attrs, srcAttrs, func(a, b Attr) bool {
+		return a.Name == b.Name
+	})
+	exts = appendMissing(exts, srcExts, func(a, b Extension) bool {
+		return a.XMLName == b.XMLName && a.InnerXML == b.InnerXML && slices.Equal(a.Attrs, b.Attrs)
+	})
+
+	return attrs, exts
+}
+
+// appendMissing appends the elements of src that have no equal in dst.
+func appendMissing[T any](dst, src []T, equal func(a, b T) bool) []T {
+	for _, v := range src {
+		if !slices.ContainsFunc(dst, func(d T) bool { return equal(d, v) }) {
+			dst = append(dst, v)
+		}
+	}
+
+	return dst
+}
+
+// equalPtr reports whether a and b are both nil or point to equal values.
+func equalPtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+// mergeRank returns the function scoring programmes under policy; higher scores
+// win.
+func mergeRank(policy MergePolicy) func(*Programme) int {
+	switch policy {
+	case MergePolicyLongestDescription:
+		return func(p *Programme) int {
+			longest := 0
+			for _, d := range p.Descriptions {
+				longest = max(longest, len(d.Text))
+			}
+
+			return longest
+		}
+	case MergePolicyMostPopulated:
+		return populatedFields
+	default:
+		return func(*Programme) int { return 0 }
+	}
+}
+
+// populatedFields counts the fields of p that are set.
+func populatedFields(p *Programme) int {
+	v := reflect.ValueOf(p).Elem()
+
+	n := 0
+
+	for i := range v.NumField() {
+		if v.Type().Field(i).Name != "XMLName" && !v.Field(i).IsZero() {
+			n++
+		}
+	}
+
+	return n
+}
@@ -0,0 +1,170 @@
+package xmltv
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func newProgramme(t *testing.T, channel, start, stop, title string) Programme {
+	t.Helper()
+
+	p := Programme{
+		Start:   Time{Time: parseTime(t, "200601021504", start)},
+		Channel: channel,
+		Titles:  []Title{{Text: title}},
+	}
+
+	if stop != "" {
+		p.Stop = &Time{Time: parseTime(t, "200601021504", stop)}
+	}
+
+	return p
+}
+
+func programmeTitles(programmes []Programme) []string {
+	titles := make([]string, len(programmes))
+	for i, p := range programmes {
+		titles[i] = p.Titles[0].Text
+	}
+
+	return titles
+}
+
+func TestMerge(t *testing.T) {
+	t.Parallel()
+
+	a := &TV{
+		SourceInfoName: makePointer("a"),
+		Channels: []Channel{
+			{ID: "one", DisplayNames: []DisplayName{{Text: "One"}}},
+		},
+		Programmes: []Programme{
+			newProgramme(t, "one", "202203311800", "202203311900", "A news"),
+			newProgramme(t, "one", "202203311900", "202203312000", "A film"),
+		},
+	}
+
+	b := &TV{
+		SourceInfoName:    makePointer("b"),
+		GeneratorInfoName: makePointer("b-grabber"),
+		Channels: []Channel{
+			{ID: "one", DisplayNames: []DisplayName{{Text: "One"}, {Text: "Channel 1"}}},
+			{ID: "two", DisplayNames: []DisplayName{{Text: "Two"}}},
+		},
+		Programmes: []Programme{
+			newProgramme(t, "one", "202203311830", "202203311930", "B overlap"),
+			newProgramme(t, "one", "202203312000", "202203312100", "B late"),
+			newProgramme(t, "two", "202203311800", "202203311900", "B two"),
+		},
+	}
+	b.Programmes[0].Descriptions = []Description{{Text: "A much longer description"}}
+
+	merged, slots := Merge(MergeOptions{}, a, b)
+
+	if diff := cmp.Diff("a", *merged.SourceInfoName); diff != "" {
+		t.Fatal(diff)
+	}
+
+	if diff := cmp.Diff("b-grabber", *merged.GeneratorInfoName); diff != "" {
+		t.Fatal(diff)
+	}
+
+	wantChannels := []Channel{
+		{ID: "one", DisplayNames: []DisplayName{{Text: "One"}, {Text: "Channel 1"}}},
+		{ID: "two", DisplayNames: []DisplayName{{Text: "Two"}}},
+	}
+	if diff := cmp.Diff(wantChannels, merged.Channels); diff != "" {
+		t.Fatal(diff)
+	}
+
+	if diff := cmp.Diff([]string{"A news", "A film", "B late", "B two"}, programmeTitles(merged.Programmes)); diff != "" {
+		t.Fatal(diff)
+	}
+
+	gotSources := make([]int, len(slots))
+	for i, s := range slots {
+		gotSources[i] = s.Source
+	}
+
+	if diff := cmp.Diff([]int{0, 0, 1, 1}, gotSources); diff != "" {
+		t.Fatal(diff)
+	}
+
+	if diff := cmp.Diff([]int{1}, slots[0].Overridden); diff != "" {
+		t.Fatal(diff)
+	}
+
+	// B overlap wins and displaces both A programmes, leaving 19:30-20:00 empty.
+	merged, _ = Merge(MergeOptions{Policy: MergePolicyLongestDescription}, a, b)
+
+	if diff := cmp.Diff([]string{"B overlap", "B late", "B two"}, programmeTitles(merged.Programmes)); diff != "" {
+		t.Fatal(diff)
+	}
+
+	if len(a.Channels[0].DisplayNames) != 1 {
+		t.Fatal("Merge modified its input")
+	}
+}
+
+func TestMergePolicies(t *testing.T) {
+	t.Parallel()
+
+	a := &TV{Programmes: []Programme{newProgramme(t, "one", "202203311800", "202203311900", "A")}}
+	a.Programmes[0].Descriptions = []Description{{Text: "A long description of the programme"}}
+
+	b := &TV{Programmes: []Programme{newProgramme(t, "one", "202203311830", "202203311930", "B")}}
+	b.Programmes[0].Categories = []Category{{Text: "News"}}
+	b.Programmes[0].Keywords = []Keyword{{Text: "politics"}}
+
+	tests := []struct {
+		name   string
+		policy MergePolicy
+		want   []string
+	}{
+		{name: "priority", policy: MergePolicyPriority, want: []string{"A"}},
+		{name: "longest description", policy: MergePolicyLongestDescription, want: []string{"A"}},
+		{name: "most populated", policy: MergePolicyMostPopulated, want: []string{"B"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			merged, slots := Merge(MergeOptions{Policy: tt.policy}, a, b)
+
+			if diff := cmp.Diff(tt.want, programmeTitles(merged.Programmes)); diff != "" {
+				t.Error(diff)
+			}
+
+			if len(slots) != 1 || len(slots[0].Overridden) != 1 {
+				t.Errorf("got slots %+v, want one overriding the other source", slots)
+			}
+		})
+	}
+}
+
+func TestMergeLongProgramme(t *testing.T) {
+	t.Parallel()
+
+	// The short programme kept between them must not hide the marathon, which
+	// started long before but still overlaps B.
+	a := &TV{Programmes: []Programme{
+		newProgramme(t, "one", "202203311800", "202203312300", "A marathon"),
+		newProgramme(t, "one", "202203311900", "202203311930", "A short"),
+	}}
+	b := &TV{Programmes: []Programme{
+		newProgramme(t, "one", "202203312200", "202203312230", "B news"),
+		newProgramme(t, "one", "202203312300", "202203312330", "B late"),
+	}}
+
+	merged, slots := Merge(MergeOptions{}, a, b)
+
+	if diff := cmp.Diff([]string{"A marathon", "A short", "B late"}, programmeTitles(merged.Programmes)); diff != "" {
+		t.Fatal(diff)
+	}
+
+	if diff := cmp.Diff([]int{1}, slots[0].Overridden); diff != "" {
+		t.Error(diff)
+	}
+}
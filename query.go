@@ -0,0 +1,623 @@
+package xmltv
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Matcher reports whether a programme is selected.
+type Matcher interface {
+	Match(p *Programme) bool
+}
+
+// MatcherFunc adapts a function to a Matcher.
+type MatcherFunc func(p *Programme) bool
+
+// Match returns f(p).
+func (f MatcherFunc) Match(p *Programme) bool {
+	return f(p)
+}
+
+// QueryError is a syntax or type error in an expression passed to Compile.
+type QueryError struct {
+	// Column is the one-based position, in runes, of the error in the
+	// expression.
+	Column  int
+	Message string
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("xmltv: query: column %d: %s", e.Column, e.Message)
+}
+
+// Filter removes the programmes that m does not match. Channels are kept; use
+// PruneChannels to drop those left without programmes.
+func Filter(tv *TV, m Matcher) {
+	tv.Programmes = slices.DeleteFunc(tv.Programmes, func(p Programme) bool {
+		return !m.Match(&p)
+	})
+}
+
+// Compile parses a query expression selecting programmes, such as
+//
+//	channel in ("one.tv", "two.tv") and title ~ "(?i)news" and category["en"] = "Sports" and start >= 18:00
+//
+// An expression combines tests with and, or, not (also written &&, || and !) and
+// parentheses. A test compares a field with a value:
+//
+//	field = value      field != value     field in (value, ...)
+//	field ~ "regexp"   field !~ "regexp"
+//	field < value      field <= value     field > value     field >= value
+//
+// A field used on its own tests that it is present, or true for yes/no fields.
+// Most fields can hold several values, such as the titles of a programme in
+// different languages; a test matches if any of them does, and != and !~ match
+// if none does. A missing field never matches a test other than != and !~.
+//
+// Text fields compare with =, ~ and in. They are channel, title, sub-title,
+// desc, category, keyword, country, language, orig-language, premiere,
+// last-chance, review, rating, episode-num, subtitles, video.aspect,
+// video.quality, audio.stereo, showview, videoplus, clumpidx, credits (any
+// person), credits.director, credits.actor, credits.actor.role, credits.writer,
+// credits.adapter, credits.producer, credits.composer, credits.editor,
+// credits.presenter, credits.commentator and credits.guest. A language, or a
+// system for rating and episode-num, may follow in brackets, as in
+// title["en"] or rating["MPAA"].
+//
+// Time fields are start, stop, date, pdc-start, vps-start and
+// previously-shown.start. They compare with a quoted time, in the XMLTV format,
+// RFC 3339 or a layout such as "2006-01-02 15:04", or with a time of day such as
+// 18:00, which is compared with the field's clock time in its own location.
+// Times without an offset are UTC.
+//
+// Duration fields are length and duration, the latter from the stop time or
+// else the length of the programme. They compare with values such as 90m or
+// 1h30m.
+//
+// Number fields are season, episode and part, from the first episode-num that
+// can be parsed, and star-rating, the number of stars awarded, optionally
+// bracketed with a system. They compare with numbers.
+//
+// Yes/no fields are new, previously-shown, video.present, video.colour and
+// audio.present. They compare with true and false.
+func Compile(expr string) (Matcher, error) {
+	tokens, err := lexQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &queryParser{tokens: tokens}
+
+	m, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != queryEOF {
+		return nil, p.errorf(tok, "unexpected %s", tok)
+	}
+
+	return m, nil
+}
+
+// MustCompile is like Compile but panics if the expression cannot be parsed.
+func MustCompile(expr string) Matcher {
+	m, err := Compile(expr)
+	if err != nil {
+		panic(err)
+	}
+
+	return m
+}
+
+type queryTokenKind int
+
+const (
+	queryEOF queryTokenKind = iota
+	queryIdent
+	queryString
+	queryNumber
+	queryDuration
+	queryClock
+	queryPunct
+)
+
+type queryToken struct {
+	kind queryTokenKind
+	text string
+	col  int
+}
+
+func (t queryToken) String() string {
+	switch t.kind {
+	case queryEOF:
+		return "end of expression"
+	case queryString:
+		return strconv.Quote(t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+// lexQuery splits expr into tokens.
+func lexQuery(expr string) ([]queryToken, error) {
+	runes := []rune(expr)
+
+	var tokens []queryToken
+
+	for i := 0; i < len(runes); {
+		r, col := runes[i], i+1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsLetter(r):
+			j := i + 1
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || strings.ContainsRune("-_.", runes[j])) {
+				j++
+			}
+
+			tokens = append(tokens, queryToken{kind: queryIdent, text: string(runes[i:j]), col: col})
+			i = j
+		case unicode.IsDigit(r):
+			j := i + 1
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.') {
+				j++
+			}
+
+			kind := queryNumber
+
+			switch {
+			case j < len(runes) && runes[j] == ':':
+				kind = queryClock
+				for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == ':') {
+					j++
+				}
+			case j < len(runes) && unicode.IsLetter(runes[j]):
+				kind = queryDuration
+				for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '.') {
+					j++
+				}
+			}
+
+			tokens = append(tokens, queryToken{kind: kind, text: string(runes[i:j]), col: col})
+			i = j
+		case r == '"' || r == '\'':
+			var b strings.Builder
+
+			j := i + 1
+			for ; j < len(runes) && runes[j] != r; j++ {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					j++
+				}
+
+				b.WriteRune(runes[j])
+			}
+
+			if j == len(runes) {
+				return nil, &QueryError{Column: col, Message: "unterminated string"}
+			}
+
+			tokens = append(tokens, queryToken{kind: queryString, text: b.String(), col: col})
+			i = j + 1
+		default:
+			op := string(r)
+			if i+1 < len(runes) {
+				if two := string(runes[i : i+2]); slices.Contains([]string{"!=", "!~", "<=", ">=", "&&", "||"}, two) {
+					op = two
+				}
+			}
+
+			if !slices.Contains([]string{"(", ")", "[", "]", ",", "=", "~", "<", ">", "!", "!=", "!~", "<=", ">=", "&&", "||"}, op) {
+				return nil, &QueryError{Column: col, Message: fmt.Sprintf("unexpected character %q", r)}
+			}
+
+			tokens = append(tokens, queryToken{kind: queryPunct, text: op, col: col})
+			i += len([]rune(op))
+		}
+	}
+
+	return append(tokens, queryToken{kind: queryEOF, col: len(runes) + 1}), nil
+}
+
+// queryParser is a recursive descent parser over the tokens of an expression.
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() queryToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != queryEOF {
+		p.pos++
+	}
+
+	return tok
+}
+
+// accept consumes the next token if it is one of texts, which are punctuation
+// or keywords.
+func (p *queryParser) accept(texts ...string) bool {
+	tok := p.peek()
+	if (tok.kind == queryPunct || tok.kind == queryIdent) && slices.Contains(texts, tok.text) {
+		p.pos++
+
+		return true
+	}
+
+	return false
+}
+
+func (p *queryParser) expect(text string) error {
+	if !p.accept(text) {
+		tok := p.peek()
+
+		return p.errorf(tok, "expected %q, found %s", text, tok)
+	}
+
+	return nil
+}
+
+func (p *queryParser) errorf(tok queryToken, format string, args ...any) error {
+	return &QueryError{Column: tok.col, Message: fmt.Sprintf(format, args...)}
+}
+
+// parseOr parses: and { ("or" | "||") and }.
+func (p *queryParser) parseOr() (Matcher, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept("or", "||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = orMatcher{left, right}
+	}
+
+	return left, nil
+}
+
+// parseAnd parses: not { ("and" | "&&") not }.
+func (p *queryParser) parseAnd() (Matcher, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept("and", "&&") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		left = andMatcher{left, right}
+	}
+
+	return left, nil
+}
+
+// parseNot parses: ("not" | "!") not | "(" or ")" | test.
+func (p *queryParser) parseNot() (Matcher, error) {
+	if p.accept("not", "!") {
+		m, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		return notMatcher{m}, nil
+	}
+
+	if p.accept("(") {
+		m, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+
+		return m, nil
+	}
+
+	return p.parseTest()
+}
+
+// parseTest parses: field [ "[" string "]" ] [ operator value | "in" "(" value
+// { "," value } ")" ].
+func (p *queryParser) parseTest() (Matcher, error) {
+	tok := p.next()
+	if tok.kind != queryIdent || isQueryKeyword(tok.text) {
+		return nil, p.errorf(tok, "expected field name, found %s", tok)
+	}
+
+	f, ok := queryFields[tok.text]
+	if !ok {
+		return nil, p.errorf(tok, "unknown field %q", tok.text)
+	}
+
+	t := &testMatcher{field: f}
+
+	if open := p.peek(); p.accept("[") {
+		if f.qualifier == "" {
+			return nil, p.errorf(open, "field %q cannot be qualified", tok.text)
+		}
+
+		q := p.next()
+		if q.kind != queryString {
+			return nil, p.errorf(q, "expected quoted %s, found %s", f.qualifier, q)
+		}
+
+		t.qualifier = &q.text
+
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+	}
+
+	op := p.peek()
+
+	switch {
+	case p.accept("in"):
+		t.op = "="
+
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+
+		for {
+			v, err := p.parseValue(tok.text, f)
+			if err != nil {
+				return nil, err
+			}
+
+			t.values = append(t.values, v)
+
+			if !p.accept(",") {
+				break
+			}
+		}
+
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+	case p.accept("=", "!=", "~", "!~", "<", "<=", ">", ">="):
+		t.op = op.text
+
+		switch {
+		case (t.op == "~" || t.op == "!~") && f.kind != queryKindString:
+			return nil, p.errorf(op, "operator %s needs a text field, %q is %s", t.op, tok.text, f.kind)
+		case (t.op == "<" || t.op == "<=" || t.op == ">" || t.op == ">=") &&
+			(f.kind == queryKindString || f.kind == queryKindBool):
+			return nil, p.errorf(op, "operator %s cannot be used with %s field %q", t.op, f.kind, tok.text)
+		}
+
+		if t.op == "~" || t.op == "!~" {
+			v := p.next()
+			if v.kind != queryString {
+				return nil, p.errorf(v, "expected quoted regular expression, found %s", v)
+			}
+
+			re, err := regexp.Compile(v.text)
+			if err != nil {
+				return nil, p.errorf(v, "invalid regular expression: %v", err)
+			}
+
+			t.re = re
+
+			break
+		}
+
+		v, err := p.parseValue(tok.text, f)
+		if err != nil {
+			return nil, err
+		}
+
+		t.values = []any{v}
+	}
+
+	return t, nil
+}
+
+// parseValue parses a literal of the kind of field f, named name.
+func (p *queryParser) parseValue(name string, f *queryField) (any, error) {
+	tok := p.next()
+
+	switch f.kind {
+	case queryKindString:
+		if tok.kind == queryString {
+			return tok.text, nil
+		}
+	case queryKindNumber:
+		if tok.kind == queryNumber {
+			n, err := strconv.ParseFloat(tok.text, 64)
+			if err != nil {
+				return nil, p.errorf(tok, "invalid number %q", tok.text)
+			}
+
+			return n, nil
+		}
+	case queryKindDuration:
+		if tok.kind == queryDuration || tok.kind == queryString {
+			d, err := time.ParseDuration(tok.text)
+			if err != nil {
+				return nil, p.errorf(tok, "invalid duration %q", tok.text)
+			}
+
+			return d, nil
+		}
+	case queryKindTime:
+		if tok.kind == queryClock || tok.kind == queryString {
+			if c, ok := parseClock(tok.text); ok {
+				return c, nil
+			}
+
+			if tok.kind == queryString {
+				if t, ok := parseQueryTime(tok.text); ok {
+					return t, nil
+				}
+			}
+
+			return nil, p.errorf(tok, "invalid time %q", tok.text)
+		}
+	case queryKindBool:
+		if tok.kind == queryIdent && (tok.text == "true" || tok.text == "false") {
+			return tok.text == "true", nil
+		}
+	}
+
+	return nil, p.errorf(tok, "expected %s value for %q, found %s", f.kind, name, tok)
+}
+
+func isQueryKeyword(s string) bool {
+	return slices.Contains([]string{"and", "or", "not", "in", "true", "false"}, s)
+}
+
+// clock is a time of day, compared with the clock time of a time field.
+type clock time.Duration
+
+// parseClock parses a time of day such as 18:00 or 18:00:30.
+func parseClock(s string) (clock, bool) {
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return clock(t.Sub(t.Truncate(24 * time.Hour))), true
+		}
+	}
+
+	return 0, false
+}
+
+// parseQueryTime parses a time in the XMLTV format or a common readable layout.
+func parseQueryTime(s string) (time.Time, bool) {
+	if t, err := parseTimeValue(s); err == nil {
+		return t.Time, true
+	}
+
+	for _, layout := range []string{time.RFC3339, time.DateTime, "2006-01-02 15:04", time.DateOnly} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+type andMatcher struct{ left, right Matcher }
+
+func (m andMatcher) Match(p *Programme) bool { return m.left.Match(p) && m.right.Match(p) }
+
+type orMatcher struct{ left, right Matcher }
+
+func (m orMatcher) Match(p *Programme) bool { return m.left.Match(p) || m.right.Match(p) }
+
+type notMatcher struct{ m Matcher }
+
+func (m notMatcher) Match(p *Programme) bool { return !m.m.Match(p) }
+
+// testMatcher tests a field of a programme. With no operator it tests that the
+// field is present, or true.
+type testMatcher struct {
+	field     *queryField
+	qualifier *string
+	op        string
+	values    []any
+	re        *regexp.Regexp
+}
+
+func (m *testMatcher) Match(p *Programme) bool {
+	values := m.field.values(p, m.qualifier)
+
+	if m.op == "" {
+		if m.field.kind == queryKindBool {
+			return slices.Contains(values, any(true))
+		}
+
+		return len(values) > 0
+	}
+
+	negate := m.op == "!=" || m.op == "!~"
+
+	for _, v := range values {
+		if m.test(v) {
+			return !negate
+		}
+	}
+
+	return negate
+}
+
+// test reports whether a single value of the field passes the test, ignoring
+// negation.
+func (m *testMatcher) test(v any) bool {
+	if m.re != nil {
+		return m.re.MatchString(v.(string))
+	}
+
+	for _, operand := range m.values {
+		c := compareQueryValues(v, operand)
+
+		var ok bool
+
+		switch m.op {
+		case "=", "!=":
+			ok = c == 0
+		case "<":
+			ok = c < 0
+		case "<=":
+			ok = c <= 0
+		case ">":
+			ok = c > 0
+		case ">=":
+			ok = c >= 0
+		}
+
+		if ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// compareQueryValues compares a field value with an operand of the same kind.
+// Booleans compare as 0 when equal and 1 otherwise.
+func compareQueryValues(v, operand any) int {
+	switch v := v.(type) {
+	case string:
+		return strings.Compare(v, operand.(string))
+	case float64:
+		return cmp.Compare(v, operand.(float64))
+	case time.Duration:
+		return cmp.Compare(v, operand.(time.Duration))
+	case time.Time:
+		if c, ok := operand.(clock); ok {
+			h, m, s := v.Clock()
+
+			return cmp.Compare(time.Duration(h)*time.Hour+time.Duration(m)*time.Minute+time.Duration(s)*time.Second, time.Duration(c))
+		}
+
+		return v.Compare(operand.(time.Time))
+	case bool:
+		if v == operand.(bool) {
+			return 0
+		}
+
+		return 1
+	}
+
+	return 1
+}
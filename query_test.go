@@ -0,0 +1,118 @@
+package xmltv
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func queryTV(t *testing.T) TV {
+	t.Helper()
+
+	news := newProgramme(t, "one", "202203311800", "202203311900", "News")
+	news.Titles = append(news.Titles, Title{Lang: makePointer("fr"), Text: "Informations"})
+	news.Categories = []Category{{Lang: makePointer("en"), Text: "News"}}
+
+	match := newProgramme(t, "one", "202203311900", "202203312100", "Football")
+	match.Categories = []Category{{Lang: makePointer("en"), Text: "Sports"}}
+	match.IsNew = true
+	match.Ratings = []Rating{{System: makePointer("BBFC"), Value: &Value{Text: "PG"}}}
+
+	film := newProgramme(t, "two", "202203312000", "202203312230", "Film")
+	film.Credits = &Credits{
+		Directors: []Director{{Text: "Jane Doe"}},
+		Actors:    []Actor{{Role: makePointer("Hero"), Text: "John Smith"}},
+	}
+	film.StarRatings = []StarRating{{Value: &Value{Text: "4/5"}}}
+	film.EpisodeNumbers = []EpisodeNumber{{System: EpisodeNumberSystemOnScreen, Text: "S02E05"}}
+	film.Length = &Length{Units: LengthUnitsMinutes, Text: makePointer(150)}
+
+	return TV{Programmes: []Programme{news, match, film}}
+}
+
+func TestCompile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{expr: `channel = "one"`, want: []string{"News", "Football"}},
+		{expr: `channel != "one"`, want: []string{"Film"}},
+		{expr: `channel in ("two", "three")`, want: []string{"Film"}},
+		{expr: `title ~ "^F"`, want: []string{"Football", "Film"}},
+		{expr: `title !~ "^F"`, want: []string{"News"}},
+		{expr: `title["fr"] = "Informations"`, want: []string{"News"}},
+		{expr: `title["de"] = "Informations"`, want: []string{}},
+		{expr: `category = "Sports" and start >= 18:30`, want: []string{"Football"}},
+		{expr: `start >= "2022-03-31 19:00" && stop <= "20220331210000 +0000"`, want: []string{"Football"}},
+		{expr: `start < 19:00 or (new and not rating["BBFC"] = "18")`, want: []string{"News", "Football"}},
+		{expr: `!new`, want: []string{"News", "Film"}},
+		{expr: `new = false`, want: []string{"News", "Film"}},
+		{expr: `credits`, want: []string{"Film"}},
+		{expr: `credits ~ "Doe"`, want: []string{"Film"}},
+		{expr: `credits.actor.role = "Hero"`, want: []string{"Film"}},
+		{expr: `star-rating >= 4`, want: []string{"Film"}},
+		{expr: `season = 2 and episode in (4, 5)`, want: []string{"Film"}},
+		{expr: `length > 2h`, want: []string{"Film"}},
+		{expr: `duration >= 2h`, want: []string{"Football", "Film"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := Compile(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tv := queryTV(t)
+			Filter(&tv, m)
+
+			if diff := cmp.Diff(tt.want, programmeTitles(tv.Programmes)); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		expr   string
+		column int
+	}{
+		{expr: `title = `, column: 9},
+		{expr: `titel = "News"`, column: 1},
+		{expr: `title = "News" and`, column: 19},
+		{expr: `(title = "News"`, column: 16},
+		{expr: `title = "News")`, column: 15},
+		{expr: `title < "News"`, column: 7},
+		{expr: `start > "yesterday"`, column: 9},
+		{expr: `season = "two"`, column: 10},
+		{expr: `channel["en"] = "one"`, column: 8},
+		{expr: `title ~ "("`, column: 9},
+		{expr: `title = "News`, column: 9},
+		{expr: `title $ "News"`, column: 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Compile(tt.expr)
+
+			var qerr *QueryError
+			if !errors.As(err, &qerr) {
+				t.Fatalf("got error %v, want a *QueryError", err)
+			}
+
+			if qerr.Column != tt.column {
+				t.Fatalf("got column %d (%v), want %d", qerr.Column, err, tt.column)
+			}
+		})
+	}
+}
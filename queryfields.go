@@ -0,0 +1,359 @@
+package xmltv
+
+import (
+	"strconv"
+	"strings"
+)
+
+// queryKind is the kind of value held by a query field.
+type queryKind int
+
+const (
+	queryKindString queryKind = iota
+	queryKindNumber
+	queryKindDuration
+	queryKindTime
+	queryKindBool
+)
+
+func (k queryKind) String() string {
+	switch k {
+	case queryKindNumber:
+		return "number"
+	case queryKindDuration:
+		return "duration"
+	case queryKindTime:
+		return "time"
+	case queryKindBool:
+		return "yes/no"
+	default:
+		return "text"
+	}
+}
+
+// queryField is a field of a programme that a query can test.
+type queryField struct {
+	kind queryKind
+	// qualifier names what may follow the field in brackets, or is empty if
+	// nothing may.
+	qualifier string
+	// values returns the values of the field, as string, float64,
+	// time.Duration, time.Time or bool according to kind, keeping only those
+	// matching q when it is not nil.
+	values func(p *Programme, q *string) []any
+}
+
+// queryFields holds the fields that a query can test, by name.
+var queryFields = map[string]*queryField{
+	"channel": {kind: queryKindString, values: func(p *Programme, _ *string) []any {
+		return []any{p.Channel}
+	}},
+	"title":         langField(func(p *Programme) []Title { return p.Titles }),
+	"sub-title":     langField(func(p *Programme) []SubTitle { return p.SubTitles }),
+	"desc":          langField(func(p *Programme) []Description { return p.Descriptions }),
+	"category":      langField(func(p *Programme) []Category { return p.Categories }),
+	"keyword":       langField(func(p *Programme) []Keyword { return p.Keywords }),
+	"country":       langField(func(p *Programme) []Country { return p.Countries }),
+	"review":        langField(func(p *Programme) []Review { return p.Reviews }),
+	"language":      langField(func(p *Programme) []Language { return optional(p.Language) }),
+	"orig-language": langField(func(p *Programme) []OriginalLanguage { return optional(p.OriginalLanguage) }),
+	"premiere":      langField(func(p *Programme) []Premiere { return optional(p.Premiere) }),
+	"last-chance":   langField(func(p *Programme) []LastChance { return optional(p.Lastchance) }),
+	"rating": {kind: queryKindString, qualifier: "system", values: func(p *Programme, q *string) []any {
+		var values []any
+
+		for _, r := range p.Ratings {
+			if qualifies(r.System, q) && r.Value != nil {
+				values = append(values, r.Value.Text)
+			}
+		}
+
+		return values
+	}},
+	"star-rating": {kind: queryKindNumber, qualifier: "system", values: func(p *Programme, q *string) []any {
+		var values []any
+
+		for _, r := range p.StarRatings {
+			if !qualifies(r.System, q) || r.Value == nil {
+				continue
+			}
+
+			stars, _, _ := strings.Cut(r.Value.Text, "/")
+			if n, err := strconv.ParseFloat(strings.TrimSpace(stars), 64); err == nil {
+				values = append(values, n)
+			}
+		}
+
+		return values
+	}},
+	"episode-num": {kind: queryKindString, qualifier: "system", values: func(p *Programme, q *string) []any {
+		var values []any
+
+		for _, n := range p.EpisodeNumbers {
+			if q == nil || n.System == *q {
+				values = append(values, n.Text)
+			}
+		}
+
+		return values
+	}},
+	"season":  episodeField(func(e Episode) *int { return e.Season }),
+	"episode": episodeField(func(e Episode) *int { return e.Episode }),
+	"part":    episodeField(func(e Episode) *int { return e.Part }),
+	"subtitles": {kind: queryKindString, values: func(p *Programme, _ *string) []any {
+		var values []any
+
+		for _, s := range p.Subtitles {
+			var typ string
+			if s.Type != nil {
+				typ = string(*s.Type)
+			}
+
+			values = append(values, typ)
+		}
+
+		return values
+	}},
+	"video.aspect": stringField(func(p *Programme) *string {
+		if p.Video == nil || p.Video.Aspect == nil {
+			return nil
+		}
+
+		return &p.Video.Aspect.Text
+	}),
+	"video.quality": stringField(func(p *Programme) *string {
+		if p.Video == nil || p.Video.Quality == nil {
+			return nil
+		}
+
+		return &p.Video.Quality.Text
+	}),
+	"audio.stereo": stringField(func(p *Programme) *string {
+		if p.Audio == nil || p.Audio.Stereo == nil {
+			return nil
+		}
+
+		return &p.Audio.Stereo.Text
+	}),
+	"showview":  stringField(func(p *Programme) *string { return p.ShowView }),
+	"videoplus": stringField(func(p *Programme) *string { return p.VideoPlus }),
+	"clumpidx":  stringField(func(p *Programme) *string { return p.ClumpIndex }),
+	"credits": creditsField(func(c *Credits) []string {
+		var names []string
+		names = append(names, personNames(c.Directors, func(d Director) string { return d.Text })...)
+		names = append(names, personNames(c.Actors, func(a Actor) string { return a.Text })...)
+		names = append(names, personNames(c.Writers, func(w Writer) string { return w.Text })...)
+		names = append(names, personNames(c.Adapters, func(a Adapter) string { return a.Text })...)
+		names = append(names, personNames(c.Producers, func(p Producer) string { return p.Text })...)
+		names = append(names, personNames(c.Composers, func(cm Composer) string { return cm.Text })...)
+		names = append(names, personNames(c.Editors, func(e Editor) string { return e.Text })...)
+		names = append(names, personNames(c.Presenters, func(p Presenter) string { return p.Text })...)
+		names = append(names, personNames(c.Commentators, func(cm Commentator) string { return cm.Text })...)
+
+		return append(names, personNames(c.Guests, func(g Guest) string { return g.Text })...)
+	}),
+	"credits.director": creditsField(func(c *Credits) []string {
+		return personNames(c.Directors, func(d Director) string { return d.Text })
+	}),
+	"credits.actor": creditsField(func(c *Credits) []string {
+		return personNames(c.Actors, func(a Actor) string { return a.Text })
+	}),
+	"credits.actor.role": creditsField(func(c *Credits) []string {
+		var roles []string
+
+		for _, a := range c.Actors {
+			if a.Role != nil {
+				roles = append(roles, *a.Role)
+			}
+		}
+
+		return roles
+	}),
+	"credits.writer": creditsField(func(c *Credits) []string {
+		return personNames(c.Writers, func(w Writer) string { return w.Text })
+	}),
+	"credits.adapter": creditsField(func(c *Credits) []string {
+		return personNames(c.Adapters, func(a Adapter) string { return a.Text })
+	}),
+	"credits.producer": creditsField(func(c *Credits) []string {
+		return personNames(c.Producers, func(p Producer) string { return p.Text })
+	}),
+	"credits.composer": creditsField(func(c *Credits) []string {
+		return personNames(c.Composers, func(cm Composer) string { return cm.Text })
+	}),
+	"credits.editor": creditsField(func(c *Credits) []string {
+		return personNames(c.Editors, func(e Editor) string { return e.Text })
+	}),
+	"credits.presenter": creditsField(func(c *Credits) []string {
+		return personNames(c.Presenters, func(p Presenter) string { return p.Text })
+	}),
+	"credits.commentator": creditsField(func(c *Credits) []string {
+		return personNames(c.Commentators, func(cm Commentator) string { return cm.Text })
+	}),
+	"credits.guest": creditsField(func(c *Credits) []string {
+		return personNames(c.Guests, func(g Guest) string { return g.Text })
+	}),
+	"start":     timeField(func(p *Programme) *Time { return &p.Start }),
+	"stop":      timeField(func(p *Programme) *Time { return p.Stop }),
+	"date":      timeField(func(p *Programme) *Time { return p.Date }),
+	"pdc-start": timeField(func(p *Programme) *Time { return p.PDCStart }),
+	"vps-start": timeField(func(p *Programme) *Time { return p.VPSStart }),
+	"previously-shown.start": timeField(func(p *Programme) *Time {
+		if p.PreviouslyShown == nil {
+			return nil
+		}
+
+		return p.PreviouslyShown.Start
+	}),
+	"length": {kind: queryKindDuration, values: func(p *Programme, _ *string) []any {
+		if p.Length == nil {
+			return nil
+		}
+
+		d, err := p.Length.Duration()
+		if err != nil {
+			return nil
+		}
+
+		return []any{d}
+	}},
+	"duration": {kind: queryKindDuration, values: func(p *Programme, _ *string) []any {
+		d, err := p.Duration()
+		if err != nil {
+			return nil
+		}
+
+		return []any{d}
+	}},
+	"new": boolField(func(p *Programme) *Bool { return &p.IsNew }),
+	"previously-shown": {kind: queryKindBool, values: func(p *Programme, _ *string) []any {
+		return []any{p.PreviouslyShown != nil}
+	}},
+	"video.present": boolField(func(p *Programme) *Bool {
+		if p.Video == nil {
+			return nil
+		}
+
+		return p.Video.Present
+	}),
+	"video.colour": boolField(func(p *Programme) *Bool {
+		if p.Video == nil {
+			return nil
+		}
+
+		return p.Video.Colour
+	}),
+	"audio.present": boolField(func(p *Programme) *Bool {
+		if p.Audio == nil {
+			return nil
+		}
+
+		return p.Audio.Present
+	}),
+}
+
+// langField returns a text field over the elements returned by elems, which may
+// be qualified with a language.
+func langField[T Localized](elems func(*Programme) []T) *queryField {
+	return &queryField{kind: queryKindString, qualifier: "language", values: func(p *Programme, q *string) []any {
+		var values []any
+
+		for _, e := range elems(p) {
+			if q == nil || e.Locale() == *q {
+				values = append(values, e.Content())
+			}
+		}
+
+		return values
+	}}
+}
+
+// qualifies reports whether an element with the optional attribute value is
+// selected by the qualifier q.
+func qualifies(value, q *string) bool {
+	return q == nil || (value != nil && *value == *q)
+}
+
+// optional returns a slice holding *v, or nil if v is nil.
+func optional[T any](v *T) []T {
+	if v == nil {
+		return nil
+	}
+
+	return []T{*v}
+}
+
+func stringField(get func(*Programme) *string) *queryField {
+	return &queryField{kind: queryKindString, values: func(p *Programme, _ *string) []any {
+		if s := get(p); s != nil {
+			return []any{*s}
+		}
+
+		return nil
+	}}
+}
+
+func timeField(get func(*Programme) *Time) *queryField {
+	return &queryField{kind: queryKindTime, values: func(p *Programme, _ *string) []any {
+		if t := get(p); t != nil {
+			return []any{t.Time}
+		}
+
+		return nil
+	}}
+}
+
+func boolField(get func(*Programme) *Bool) *queryField {
+	return &queryField{kind: queryKindBool, values: func(p *Programme, _ *string) []any {
+		if b := get(p); b != nil {
+			return []any{bool(*b)}
+		}
+
+		return nil
+	}}
+}
+
+func creditsField(names func(*Credits) []string) *queryField {
+	return &queryField{kind: queryKindString, values: func(p *Programme, _ *string) []any {
+		if p.Credits == nil {
+			return nil
+		}
+
+		var values []any
+		for _, name := range names(p.Credits) {
+			values = append(values, name)
+		}
+
+		return values
+	}}
+}
+
+func personNames[T any](people []T, name func(T) string) []string {
+	names := make([]string, len(people))
+	for i, person := range people {
+		names[i] = name(person)
+	}
+
+	return names
+}
+
+// episodeField returns a number field taken from the first of a programme's
+// episode numbers that can be parsed.
+func episodeField(get func(Episode) *int) *queryField {
+	return &queryField{kind: queryKindNumber, values: func(p *Programme, _ *string) []any {
+		for _, n := range p.EpisodeNumbers {
+			e, err := n.Episode()
+			if err != nil {
+				continue
+			}
+
+			if v := get(e); v != nil {
+				return []any{float64(*v)}
+			}
+
+			return nil
+		}
+
+		return nil
+	}}
+}
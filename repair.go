@@ -0,0 +1,229 @@
+package xmltv
+
+import (
+	"slices"
+	"time"
+)
+
+// OverlapStrategy selects how TV.RemoveOverlaps resolves two overlapping
+// programmes.
+type OverlapStrategy int
+
+const (
+	// OverlapStrategyTrimStop moves the stop time of the earlier programme back to
+	// the start of the later one.
+	OverlapStrategyTrimStop OverlapStrategy = iota
+	// OverlapStrategyDelayStart moves the start time of the later programme
+	// forward to the stop of the earlier one.
+	OverlapStrategyDelayStart
+	// OverlapStrategyDropShorter removes the shorter programme.
+	OverlapStrategyDropShorter
+	// OverlapStrategyDropLessComplete removes the programme with fewer populated
+	// fields.
+	OverlapStrategyDropLessComplete
+)
+
+// RepairAction is the kind of change recorded by a Repair.
+type RepairAction string
+
+const (
+	RepairActionTrimStop   RepairAction = "trim-stop"
+	RepairActionDelayStart RepairAction = "delay-start"
+	RepairActionDrop       RepairAction = "drop"
+)
+
+// Repair records a change made by TV.RemoveOverlaps. Programmes are referred to
+// by their index in TV.Programmes before any programme was removed.
+type Repair struct {
+	Action  RepairAction `json:"action"`
+	Channel string       `json:"channel"`
+	// Programme is the programme that was changed or removed.
+	Programme int `json:"programme"`
+	// Overlapped is the programme it overlapped.
+	Overlapped int `json:"overlapped"`
+	// Old and New are the stop or start time before and after a trim or delay.
+	Old *time.Time `json:"old,omitempty"`
+	New *time.Time `json:"new,omitempty"`
+}
+
+// RemoveOverlaps makes each channel's schedule free of overlaps by applying
+// strategy to every pair of overlapping programmes, like the
+// tv_remove_some_overlapping tool. The programmes of a clump are treated as a
+// single unit: they are never separated and are changed or removed together.
+// Programmes left with a start time not before their stop time are removed. It
+// returns a log of every change made.
+func (tv *TV) RemoveOverlaps(strategy OverlapStrategy) []Repair {
+	r := repairer{tv: tv, strategy: strategy, dropped: make(map[int]bool)}
+
+	for _, channel := range tv.scheduleChannels() {
+		order := channel.programmes
+
+		// Delaying a start can move a programme past the ones after it, so the
+		// channel is re-sorted and checked again until nothing changes.
+		for {
+			repairs := len(r.repairs)
+
+			var prev []int
+
+			for _, unit := range clumps(tv.Programmes, order) {
+				if prev == nil || !overlaps(&tv.Programmes[prev[0]], &tv.Programmes[unit[0]]) {
+					prev = unit
+
+					continue
+				}
+
+				prev = r.resolve(channel.id, prev, unit)
+			}
+
+			if len(r.repairs) == repairs {
+				break
+			}
+
+			order = slices.DeleteFunc(order, func(i int) bool { return r.dropped[i] })
+			slices.SortStableFunc(order, func(a, b int) int {
+				return tv.Programmes[a].Start.Compare(tv.Programmes[b].Start.Time)
+			})
+		}
+	}
+
+	i := 0
+	tv.Programmes = slices.DeleteFunc(tv.Programmes, func(Programme) bool {
+		i++
+
+		return r.dropped[i-1]
+	})
+
+	return r.repairs
+}
+
+// repairer holds the state of a RemoveOverlaps run.
+type repairer struct {
+	tv       *TV
+	strategy OverlapStrategy
+	dropped  map[int]bool
+	repairs  []Repair
+}
+
+// resolve resolves the overlap between the clumps earlier and later, returning
+// the clump that later programmes must be checked against.
+func (r *repairer) resolve(channel string, earlier, later []int) []int {
+	first, second := &r.tv.Programmes[earlier[0]], &r.tv.Programmes[later[0]]
+
+	switch r.strategy {
+	case OverlapStrategyTrimStop:
+		if !first.Start.Before(second.Start.Time) {
+			r.drop(channel, earlier, later[0])
+
+			return later
+		}
+
+		newStop := second.Start.Time
+		for _, i := range earlier {
+			// Clump members without a stop time, or that stop in time, are
+			// left alone.
+			p := &r.tv.Programmes[i]
+			if p.Stop == nil || !p.Stop.After(newStop) {
+				continue
+			}
+
+			old := p.Stop.Time
+			p.Stop = &Time{Time: newStop, Layout: p.Stop.Layout}
+			r.repairs = append(r.repairs, Repair{
+				Action:     RepairActionTrimStop,
+				Channel:    channel,
+				Programme:  i,
+				Overlapped: later[0],
+				Old:        &old,
+				New:        &newStop,
+			})
+		}
+
+		return later
+	case OverlapStrategyDelayStart:
+		if first.Stop == nil || (second.Stop != nil && !first.Stop.Before(second.Stop.Time)) {
+			r.drop(channel, later, earlier[0])
+
+			return earlier
+		}
+
+		newStart := first.Stop.Time
+		for _, i := range later {
+			p := &r.tv.Programmes[i]
+			old := p.Start.Time
+			p.Start = Time{Time: newStart, Layout: p.Start.Layout}
+			r.repairs = append(r.repairs, Repair{
+				Action:     RepairActionDelayStart,
+				Channel:    channel,
+				Programme:  i,
+				Overlapped: earlier[0],
+				Old:        &old,
+				New:        &newStart,
+			})
+		}
+
+		// Clump members stopping before the new start are now empty.
+		var empty, kept []int
+		for _, i := range later {
+			if p := &r.tv.Programmes[i]; p.Stop != nil && !p.Start.Before(p.Stop.Time) {
+				empty = append(empty, i)
+			} else {
+				kept = append(kept, i)
+			}
+		}
+
+		r.drop(channel, empty, earlier[0])
+
+		if kept == nil {
+			return earlier
+		}
+
+		return kept
+	case OverlapStrategyDropLessComplete:
+		if populatedFields(first) < populatedFields(second) {
+			r.drop(channel, earlier, later[0])
+
+			return later
+		}
+
+		r.drop(channel, later, earlier[0])
+
+		return earlier
+	default:
+		if end(first).Sub(first.Start.Time) < end(second).Sub(second.Start.Time) {
+			r.drop(channel, earlier, later[0])
+
+			return later
+		}
+
+		r.drop(channel, later, earlier[0])
+
+		return earlier
+	}
+}
+
+// drop removes the programmes of clump, which overlapped the programme at index
+// overlapped.
+func (r *repairer) drop(channel string, clump []int, overlapped int) {
+	for _, i := range clump {
+		r.dropped[i] = true
+		r.repairs = append(r.repairs, Repair{Action: RepairActionDrop, Channel: channel, Programme: i, Overlapped: overlapped})
+	}
+}
+
+// clumps splits the indexes of a channel's programmes, sorted by start time,
+// into units: single programmes or all the programmes of a clump.
+func clumps(programmes []Programme, order []int) [][]int {
+	var units [][]int
+
+	for _, i := range order {
+		if n := len(units); n > 0 && sameClump(&programmes[units[n-1][0]], &programmes[i]) {
+			units[n-1] = append(units[n-1], i)
+
+			continue
+		}
+
+		units = append(units, []int{i})
+	}
+
+	return units
+}
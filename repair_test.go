@@ -0,0 +1,205 @@
+package xmltv
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func overlappingTV(t *testing.T) TV {
+	t.Helper()
+
+	tv := TV{
+		Programmes: []Programme{
+			newProgramme(t, "one", "202203311800", "202203311910", "News"),
+			newProgramme(t, "one", "202203311900", "202203312100", "Film"),
+			newProgramme(t, "one", "202203312100", "202203312200", "Clump A"),
+			newProgramme(t, "one", "202203312100", "202203312200", "Clump B"),
+			newProgramme(t, "two", "202203311800", "202203311900", "Alone"),
+		},
+	}
+	tv.Programmes[0].Descriptions = []Description{{Text: "Headlines"}}
+	tv.Programmes[2].ClumpIndex = makePointer("0/2")
+	tv.Programmes[3].ClumpIndex = makePointer("1/2")
+
+	return tv
+}
+
+func TestRemoveOverlaps(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		strategy OverlapStrategy
+		// programmes replaces those of overlappingTV when set.
+		programmes []string
+		want       []string
+		actions    []RepairAction
+	}{
+		{
+			name:     "trim stop",
+			strategy: OverlapStrategyTrimStop,
+			want:     []string{"News 1800-1900", "Film 1900-2100", "Clump A 2100-2200", "Clump B 2100-2200", "Alone 1800-1900"},
+			actions:  []RepairAction{RepairActionTrimStop},
+		},
+		{
+			name:     "delay start",
+			strategy: OverlapStrategyDelayStart,
+			want:     []string{"News 1800-1910", "Film 1910-2100", "Clump A 2100-2200", "Clump B 2100-2200", "Alone 1800-1900"},
+			actions:  []RepairAction{RepairActionDelayStart},
+		},
+		{
+			name:     "drop shorter",
+			strategy: OverlapStrategyDropShorter,
+			want:     []string{"Film 1900-2100", "Clump A 2100-2200", "Clump B 2100-2200", "Alone 1800-1900"},
+			actions:  []RepairAction{RepairActionDrop},
+		},
+		{
+			name:     "drop less complete",
+			strategy: OverlapStrategyDropLessComplete,
+			want:     []string{"News 1800-1910", "Clump A 2100-2200", "Clump B 2100-2200", "Alone 1800-1900"},
+			actions:  []RepairAction{RepairActionDrop},
+		},
+		{
+			// Delaying Second moves it past Third, which still overlaps First.
+			name:       "delay start past a later programme",
+			strategy:   OverlapStrategyDelayStart,
+			programmes: []string{"First 1800-2000", "Second 1830-2100", "Third 1900-1930"},
+			want:       []string{"First 1800-2000", "Second 2000-2100"},
+			actions:    []RepairAction{RepairActionDelayStart, RepairActionDrop},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tv := overlappingTV(t)
+			if tt.programmes != nil {
+				tv.Programmes = nil
+
+				for _, p := range tt.programmes {
+					title, times, _ := strings.Cut(p, " ")
+					start, stop, _ := strings.Cut(times, "-")
+					tv.Programmes = append(tv.Programmes, newProgramme(t, "one", "20220331"+start, "20220331"+stop, title))
+				}
+			}
+
+			repairs := tv.RemoveOverlaps(tt.strategy)
+
+			got := make([]string, len(tv.Programmes))
+			for i, p := range tv.Programmes {
+				got[i] = p.Titles[0].Text + " " + p.Start.Format("1504") + "-" + p.Stop.Format("1504")
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Fatal(diff)
+			}
+
+			actions := make([]RepairAction, len(repairs))
+			for i, r := range repairs {
+				actions[i] = r.Action
+			}
+
+			if diff := cmp.Diff(tt.actions, actions); diff != "" {
+				t.Fatal(diff)
+			}
+
+			if !tv.Analyze(AnalyzeOptions{MinGap: 24 * time.Hour}).OK() {
+				t.Fatal("schedule still has overlaps after repair")
+			}
+		})
+	}
+}
+
+func TestRemoveOverlapsDropsClumpTogether(t *testing.T) {
+	t.Parallel()
+
+	tv := overlappingTV(t)
+	tv.Programmes = append(tv.Programmes, newProgramme(t, "one", "202203312130", "202203312330", "Overrun"))
+
+	repairs := tv.RemoveOverlaps(OverlapStrategyDropShorter)
+
+	if diff := cmp.Diff([]string{"Film", "Alone", "Overrun"}, programmeTitles(tv.Programmes)); diff != "" {
+		t.Fatal(diff)
+	}
+
+	if diff := cmp.Diff(3, len(repairs)); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestRemoveOverlapsClumpMembers(t *testing.T) {
+	t.Parallel()
+
+	t.Run("trim stop of member without stop", func(t *testing.T) {
+		t.Parallel()
+
+		tv := TV{
+			Programmes: []Programme{
+				newProgramme(t, "one", "202203310000", "202203310100", "A"),
+				newProgramme(t, "one", "202203310000", "", "B"),
+				newProgramme(t, "one", "202203310030", "202203310130", "C"),
+			},
+		}
+		tv.Programmes[0].ClumpIndex = makePointer("0/2")
+		tv.Programmes[1].ClumpIndex = makePointer("1/2")
+
+		repairs := tv.RemoveOverlaps(OverlapStrategyTrimStop)
+
+		if diff := cmp.Diff("0030", tv.Programmes[0].Stop.Format("1504")); diff != "" {
+			t.Error(diff)
+		}
+
+		if tv.Programmes[1].Stop != nil {
+			t.Errorf("got stop %v for B, want none", tv.Programmes[1].Stop)
+		}
+
+		if diff := cmp.Diff([]Repair{{
+			Action:     RepairActionTrimStop,
+			Channel:    "one",
+			Programme:  0,
+			Overlapped: 2,
+			Old:        makePointer(parseTime(t, "200601021504", "202203310100")),
+			New:        makePointer(parseTime(t, "200601021504", "202203310030")),
+		}}, repairs); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("delay start past member stop", func(t *testing.T) {
+		t.Parallel()
+
+		tv := TV{
+			Programmes: []Programme{
+				newProgramme(t, "one", "202203310000", "202203310100", "A"),
+				newProgramme(t, "one", "202203310030", "202203310200", "B"),
+				newProgramme(t, "one", "202203310030", "202203310045", "C"),
+			},
+		}
+		tv.Programmes[1].ClumpIndex = makePointer("0/2")
+		tv.Programmes[2].ClumpIndex = makePointer("1/2")
+
+		repairs := tv.RemoveOverlaps(OverlapStrategyDelayStart)
+
+		if diff := cmp.Diff([]string{"A", "B"}, programmeTitles(tv.Programmes)); diff != "" {
+			t.Fatal(diff)
+		}
+
+		if diff := cmp.Diff("0100", tv.Programmes[1].Start.Format("1504")); diff != "" {
+			t.Error(diff)
+		}
+
+		actions := make([]RepairAction, len(repairs))
+		for i, r := range repairs {
+			actions[i] = r.Action
+		}
+
+		want := []RepairAction{RepairActionDelayStart, RepairActionDelayStart, RepairActionDrop}
+		if diff := cmp.Diff(want, actions); diff != "" {
+			t.Error(diff)
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package xmltv
+
+import (
+	"slices"
+	"time"
+)
+
+// end returns the instant at which p stops occupying its channel. A programme
+// without a stop time, or whose stop is not after its start, occupies only its
+// start instant.
+func end(p *Programme) time.Time {
+	if p.Stop != nil && p.Stop.After(p.Start.Time) {
+		return p.Stop.Time
+	}
+
+	return p.Start.Add(time.Nanosecond)
+}
+
+// overlaps reports whether a and b occupy any common instant.
+func overlaps(a, b *Programme) bool {
+	return a.Start.Before(end(b)) && b.Start.Before(end(a))
+}
+
+// startIndex holds the sorted, distinct start times of each channel's
+// programmes, so that the end of a programme without a stop time can be
+// inferred from the next programme on the same channel.
+type startIndex map[string][]time.Time
+
+func newStartIndex(programmes []Programme) startIndex {
+	idx := make(startIndex)
+	for i := range programmes {
+		idx[programmes[i].Channel] = append(idx[programmes[i].Channel], programmes[i].Start.Time)
+	}
+
+	for channel, starts := range idx {
+		slices.SortFunc(starts, time.Time.Compare)
+		idx[channel] = slices.CompactFunc(starts, time.Time.Equal)
+	}
+
+	return idx
+}
+
+// next returns the first start time on channel after t.
+func (idx startIndex) next(channel string, t time.Time) (time.Time, bool) {
+	starts := idx[channel]
+
+	i, found := slices.BinarySearchFunc(starts, t, time.Time.Compare)
+	if found {
+		i++
+	}
+
+	if i == len(starts) {
+		return time.Time{}, false
+	}
+
+	return starts[i], true
+}
+
+// stop returns p's stop time or, when it has none, the start of the next
+// programme on its channel. The last programme of a channel without a stop time
+// is treated as stopping when it starts.
+func (idx startIndex) stop(p *Programme) time.Time {
+	if p.Stop != nil {
+		return p.Stop.Time
+	}
+
+	if next, ok := idx.next(p.Channel, p.Start.Time); ok {
+		return next
+	}
+
+	return p.Start.Time
+}
+
+// scheduledChannel is a channel with the indexes of its programmes in
+// TV.Programmes, sorted by start time.
+type scheduledChannel struct {
+	id         string
+	programmes []int
+}
+
+// scheduleChannels groups the programmes of tv by channel. Channels are ordered
+// as in tv.Channels, followed by channels that only appear in programmes.
+func (tv *TV) scheduleChannels() []scheduledChannel {
+	var channels []scheduledChannel
+
+	index := make(map[string]int)
+
+	for _, c := range tv.Channels {
+		if _, ok := index[c.ID]; !ok {
+			index[c.ID] = len(channels)
+			channels = append(channels, scheduledChannel{id: c.ID})
+		}
+	}
+
+	for i, p := range tv.Programmes {
+		n, ok := index[p.Channel]
+		if !ok {
+			n = len(channels)
+			index[p.Channel] = n
+			channels = append(channels, scheduledChannel{id: p.Channel})
+		}
+
+		channels[n].programmes = append(channels[n].programmes, i)
+	}
+
+	for _, c := range channels {
+		slices.SortStableFunc(c.programmes, func(a, b int) int {
+			return tv.Programmes[a].Start.Compare(tv.Programmes[b].Start.Time)
+		})
+	}
+
+	return channels
+}
+
+// sameClump reports whether a and b belong to the same clump, a group of
+// programmes sharing a time slot whose exact boundaries are unknown.
+func sameClump(a, b *Programme) bool {
+	if a.ClumpIndex == nil || b.ClumpIndex == nil || !a.Start.Equal(b.Start.Time) {
+		return false
+	}
+
+	ai, ac, aok := parseClumpIndex(*a.ClumpIndex)
+	bi, bc, bok := parseClumpIndex(*b.ClumpIndex)
+
+	return aok && bok && ac > 1 && ac == bc && ai != bi
+}
+
+// channelGaps returns the gaps between the programmes of a channel, given by
+// their indexes sorted by start time. It tracks the latest stop time seen, so
+// that a gap is only reported when no earlier programme is still on air.
+func channelGaps(programmes []Programme, order []int, starts startIndex) []Gap {
+	var (
+		gaps     []Gap
+		last     = -1
+		lastStop time.Time
+	)
+
+	for _, i := range order {
+		p := &programmes[i]
+
+		if last >= 0 && p.Start.After(lastStop) {
+			gaps = append(gaps, Gap{After: last, Before: i, Start: lastStop, Stop: p.Start.Time, Duration: p.Start.Sub(lastStop)})
+		}
+
+		stop := starts.stop(p)
+		if stop.Before(p.Start.Time) {
+			stop = p.Start.Time
+		}
+
+		if last < 0 || stop.After(lastStop) {
+			last, lastStop = i, stop
+		}
+	}
+
+	return gaps
+}
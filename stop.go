@@ -0,0 +1,92 @@
+package xmltv
+
+import (
+	"slices"
+	"time"
+)
+
+// StopMismatch reports a programme whose inferred stop time disagrees with its
+// Length.
+type StopMismatch struct {
+	// Programme is the index of the programme in the sorted TV.Programmes.
+	Programme int       `json:"programme"`
+	Channel   string    `json:"channel"`
+	Inferred  time.Time `json:"inferred"`
+	// Length is the duration given by the programme's Length element.
+	Length time.Duration `json:"length"`
+	// Difference is the inferred duration minus Length.
+	Difference time.Duration `json:"difference"`
+}
+
+// SortByChannel orders the programmes by channel, in the order of tv.Channels
+// followed by channels only appearing in programmes, and then by start time, like
+// tv_sort --by-channel. Programmes with equal channel and start keep their
+// relative order.
+func (tv *TV) SortByChannel() {
+	channels := tv.scheduleChannels()
+
+	sorted := make([]Programme, 0, len(tv.Programmes))
+	for _, c := range channels {
+		for _, i := range c.programmes {
+			sorted = append(sorted, tv.Programmes[i])
+		}
+	}
+
+	tv.Programmes = sorted
+}
+
+// FillStops sorts the programmes with SortByChannel and sets every missing stop
+// time to the start of the next programme on the same channel. The last
+// programme of a channel gets a stop time from its Length, if it has one.
+//
+// When a programme with an inferred stop time also has a Length, the two are
+// compared, and disagreements by more than tolerance are reported.
+func (tv *TV) FillStops(tolerance time.Duration) []StopMismatch {
+	tv.SortByChannel()
+
+	var mismatches []StopMismatch
+
+	for i := range tv.Programmes {
+		p := &tv.Programmes[i]
+		if p.Stop != nil {
+			continue
+		}
+
+		var length *time.Duration
+		if p.Length != nil {
+			if d, err := p.Length.Duration(); err == nil {
+				length = &d
+			}
+		}
+
+		next := slices.IndexFunc(tv.Programmes[i+1:], func(n Programme) bool {
+			return n.Channel != p.Channel || n.Start.After(p.Start.Time)
+		})
+
+		if next < 0 || tv.Programmes[i+1+next].Channel != p.Channel {
+			if length != nil && *length > 0 {
+				p.Stop = &Time{Time: p.Start.Add(*length), Layout: p.Start.Layout}
+			}
+
+			continue
+		}
+
+		nextStart := tv.Programmes[i+1+next].Start
+		p.Stop = &Time{Time: nextStart.Time, Layout: nextStart.Layout}
+
+		if length != nil {
+			diff := nextStart.Sub(p.Start.Time) - *length
+			if diff > tolerance || diff < -tolerance {
+				mismatches = append(mismatches, StopMismatch{
+					Programme:  i,
+					Channel:    p.Channel,
+					Inferred:   nextStart.Time,
+					Length:     *length,
+					Difference: diff,
+				})
+			}
+		}
+	}
+
+	return mismatches
+}
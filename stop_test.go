@@ -0,0 +1,62 @@
+package xmltv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFillStops(t *testing.T) {
+	t.Parallel()
+
+	tv := TV{
+		Channels: []Channel{{ID: "one"}, {ID: "two"}},
+		Programmes: []Programme{
+			newProgramme(t, "two", "202203311800", "", "Two news"),
+			newProgramme(t, "one", "202203311900", "", "Film"),
+			newProgramme(t, "one", "202203311800", "", "News"),
+			newProgramme(t, "one", "202203312100", "", "Late"),
+			newProgramme(t, "two", "202203311830", "202203311900", "Two film"),
+		},
+	}
+	tv.Programmes[1].Length = &Length{Units: LengthUnitsMinutes, Text: makePointer(90)}
+	tv.Programmes[2].Length = &Length{Units: LengthUnitsHours, Text: makePointer(1)}
+	tv.Programmes[3].Length = &Length{Units: LengthUnitsSeconds, Text: makePointer(1800)}
+
+	mismatches := tv.FillStops(5 * time.Minute)
+
+	got := make([]string, len(tv.Programmes))
+	for i, p := range tv.Programmes {
+		stop := "open"
+		if p.Stop != nil {
+			stop = p.Stop.Format("1504")
+		}
+
+		got[i] = p.Titles[0].Text + " " + p.Start.Format("1504") + "-" + stop
+	}
+
+	want := []string{
+		"News 1800-1900",
+		"Film 1900-2100",
+		"Late 2100-2130",
+		"Two news 1800-1830",
+		"Two film 1830-1900",
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatal(diff)
+	}
+
+	wantMismatches := []StopMismatch{{
+		Programme:  1,
+		Channel:    "one",
+		Inferred:   parseTime(t, "200601021504", "202203312100"),
+		Length:     90 * time.Minute,
+		Difference: 30 * time.Minute,
+	}}
+
+	if diff := cmp.Diff(wantMismatches, mismatches); diff != "" {
+		t.Fatal(diff)
+	}
+}
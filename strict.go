@@ -0,0 +1,269 @@
+package xmltv
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrUnknownElement is wrapped by the DecodeError reported for an element
+	// not defined by the DTD in strict mode.
+	ErrUnknownElement = errors.New("unknown element")
+
+	// ErrUnknownAttribute is wrapped by the DecodeError reported for an attribute
+	// not defined by the DTD in strict mode.
+	ErrUnknownAttribute = errors.New("unknown attribute")
+
+	// ErrInvalidValue is wrapped by the DecodeError reported for a malformed
+	// value, or for text where the DTD allows none, in strict mode.
+	ErrInvalidValue = errors.New("invalid value")
+)
+
+// elementFields describes the attributes, child elements and text an element
+// decoded into a struct may have.
+type elementFields struct {
+	// name is the element name given by the XMLName field.
+	name     string
+	attrs    map[string]reflect.Type
+	elements map[string]reflect.Type
+	// text is the type of the field holding the element's text, or nil if the
+	// element has none.
+	text reflect.Type
+	// fields lists the attribute and child element fields in the order they
+	// are declared, and so written.
+	fields []xmlField
+}
+
+// xmlField is a struct field holding an attribute or a child element.
+type xmlField struct {
+	name  string
+	index int
+	attr  bool
+	// any is set for the field holding the attributes not defined by the DTD.
+	any       bool
+	omitempty bool
+}
+
+var (
+	timeType       = reflect.TypeFor[Time]()
+	boolType       = reflect.TypeFor[Bool]()
+	imageSizeType  = reflect.TypeFor[ImageSize]()
+	elementsByType sync.Map // map[reflect.Type]*elementFields
+)
+
+// strictEnums lists the values the DTD allows for enumerated types.
+var strictEnums = map[reflect.Type][]string{
+	reflect.TypeFor[LengthUnits]():      {string(LengthUnitsSeconds), string(LengthUnitsMinutes), string(LengthUnitsHours)},
+	reflect.TypeFor[SubtitlesType]():    {string(SubtitlesTypeTeletext), string(SubtitlesTypeOnScreen), string(SubtitlesTypeDeafSigned)},
+	reflect.TypeFor[ReviewType]():       {string(ReviewTypeText), string(ReviewTypeURL)},
+	reflect.TypeFor[ImageOrientation](): {string(ImageOrientationPortrait), string(ImageOrientationLandscape)},
+	reflect.TypeFor[ImageType](): {
+		string(ImageTypePoster), string(ImageTypeBackdrop), string(ImageTypeStill), string(ImageTypePerson),
+		string(ImageTypeCharacter),
+	},
+}
+
+// fieldsOf returns the element description of the struct type t, derived from
+// its xml struct tags.
+func fieldsOf(t reflect.Type) *elementFields {
+	if f, ok := elementsByType.Load(t); ok {
+		return f.(*elementFields)
+	}
+
+	f := &elementFields{attrs: make(map[string]reflect.Type), elements: make(map[string]reflect.Type)}
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+
+		tag, ok := field.Tag.Lookup("xml")
+		if !ok || tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		flags := strings.Split(opts, ",")
+		omitempty := slices.Contains(flags, "omitempty")
+
+		switch {
+		case field.Name == "XMLName":
+			f.name = name
+		case slices.Contains(flags, "any"):
+			// Catch-alls for content outside the DTD. The element catch-all is
+			// handled by the Extensions code.
+			if slices.Contains(flags, "attr") {
+				f.fields = append(f.fields, xmlField{index: i, attr: true, any: true})
+			}
+		case slices.Contains(flags, "chardata"):
+			f.text = field.Type
+		case slices.Contains(flags, "attr"):
+			f.attrs[name] = field.Type
+			f.fields = append(f.fields, xmlField{name: name, index: i, attr: true, omitempty: omitempty})
+		default:
+			f.elements[name] = field.Type
+			f.fields = append(f.fields, xmlField{name: name, index: i, omitempty: omitempty})
+		}
+	}
+
+	elementsByType.Store(t, f)
+
+	return f
+}
+
+// strictChecker checks the tokens of an element against the Go type it is
+// decoded into.
+type strictChecker struct {
+	tokens []positionedToken
+	i      int
+}
+
+// checkStrict checks the element held in tokens, whose first token is its start
+// tag, against the type of v, which it is decoded into. path is the path of the
+// element.
+func checkStrict(tokens []positionedToken, v any, path string) error {
+	c := &strictChecker{tokens: tokens}
+
+	return c.element(reflect.TypeOf(v), path)
+}
+
+// element checks the element starting at the current token, decoded into a
+// value of type t, and consumes its tokens.
+func (c *strictChecker) element(t reflect.Type, path string) error {
+	for t.Kind() == reflect.Pointer || (t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8) {
+		t = t.Elem()
+	}
+
+	start := c.tokens[c.i]
+	c.i++
+
+	var f *elementFields
+	if t.Kind() == reflect.Struct && t != timeType {
+		f = fieldsOf(t)
+	} else {
+		f = &elementFields{text: t}
+	}
+
+	for _, attr := range start.tok.(xml.StartElement).Attr {
+		attrPath := path + "/@" + attrName(attr.Name)
+
+		at, ok := f.attrs[attr.Name.Local]
+		if !ok || attr.Name.Space != "" {
+			return &DecodeError{Line: start.pos.line, Column: start.pos.column, Offset: start.pos.offset, Path: attrPath, Err: ErrUnknownAttribute}
+		}
+
+		if err := checkValue(at, attr.Value, ""); err != nil {
+			return &DecodeError{Line: start.pos.line, Column: start.pos.column, Offset: start.pos.offset, Path: attrPath, Err: err}
+		}
+	}
+
+	var (
+		text   strings.Builder
+		counts = make(map[string]int)
+	)
+
+	for {
+		tok := c.tokens[c.i]
+
+		switch tok := tok.tok.(type) {
+		case xml.StartElement:
+			counts[tok.Name.Local]++
+			childPath := fmt.Sprintf("%s/%s[%d]", path, tok.Name.Local, counts[tok.Name.Local])
+
+			et, ok := f.elements[tok.Name.Local]
+			if !ok || (tok.Name.Space != "" && tok.Name.Space != start.tok.(xml.StartElement).Name.Space) {
+				pos := c.tokens[c.i].pos
+
+				return &DecodeError{Line: pos.line, Column: pos.column, Offset: pos.offset, Path: childPath, Err: ErrUnknownElement}
+			}
+
+			if err := c.element(et, childPath); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			c.i++
+
+			value := text.String()
+
+			var err error
+			if f.text == nil {
+				if strings.TrimSpace(value) != "" {
+					err = fmt.Errorf("%w: unexpected text %q", ErrInvalidValue, strings.TrimSpace(value))
+				}
+			} else {
+				err = checkValue(f.text, value, tok.Name.Local)
+			}
+
+			if err != nil {
+				return &DecodeError{Line: start.pos.line, Column: start.pos.column, Offset: start.pos.offset, Path: path, Err: err}
+			}
+
+			return nil
+		case xml.CharData:
+			text.Write(tok)
+
+			c.i++
+		default:
+			c.i++
+		}
+	}
+}
+
+// checkValue checks the value of an attribute or the text of an element, named
+// element, against the type t it is decoded into.
+func checkValue(t reflect.Type, value, element string) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	invalid := func() error {
+		return fmt.Errorf("%w %q", ErrInvalidValue, value)
+	}
+
+	switch {
+	case t == timeType:
+		if _, err := parseTimeValue(strings.TrimSpace(value)); err != nil {
+			return invalid()
+		}
+	case t == boolType:
+		// <new/> is an empty element; other yes/no values are "yes" or "no".
+		if element == "new" {
+			if strings.TrimSpace(value) != "" {
+				return invalid()
+			}
+		} else if v := strings.TrimSpace(value); v != "yes" && v != "no" {
+			return invalid()
+		}
+	case t == imageSizeType:
+		if n, err := strconv.Atoi(strings.TrimSpace(value)); err != nil || n < int(ImageSizeSmall) || n > int(ImageSizeLarge) {
+			return invalid()
+		}
+	case strictEnums[t] != nil:
+		if !slices.Contains(strictEnums[t], strings.TrimSpace(value)) {
+			return invalid()
+		}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64:
+		if _, err := strconv.ParseInt(strings.TrimSpace(value), 10, t.Bits()); err != nil {
+			return invalid()
+		}
+	case t.Kind() >= reflect.Uint && t.Kind() <= reflect.Uint64:
+		if _, err := strconv.ParseUint(strings.TrimSpace(value), 10, t.Bits()); err != nil {
+			return invalid()
+		}
+	}
+
+	return nil
+}
+
+// attrName returns the name of an attribute as written, for a path.
+func attrName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+
+	return name.Space + ":" + name.Local
+}
@@ -0,0 +1,172 @@
+package xmltv
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDecoderStrict(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.Open("testdata/unmarshal/epg.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	want, err := os.ReadFile("testdata/unmarshal/epg.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lax TV
+	if err := NewDecoder(strings.NewReader(string(want))).Decode(&lax); err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewDecoder(f)
+	d.SetMode(DecodeModeStrict)
+
+	var tv TV
+	if err := d.Decode(&tv); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(lax, tv); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestDecoderStrictViolations(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		attrs     string
+		programme string
+		line, col int
+		path      string
+		err       error
+		// laxErr is set when the default mode also fails to decode.
+		laxErr bool
+	}{
+		{
+			name:      "misspelled element",
+			programme: "<title>News</title>\n    <subtitle>Oops</subtitle>",
+			line:      6, col: 5,
+			path: "/tv/programme[1]/subtitle[1]",
+			err:  ErrUnknownElement,
+		},
+		{
+			name:      "unknown attribute",
+			programme: `<title catchup-id="1">News</title>`,
+			line:      5, col: 5,
+			path: "/tv/programme[1]/title[1]/@catchup-id",
+			err:  ErrUnknownAttribute,
+		},
+		{
+			name:      "bool attribute",
+			programme: "<title>News</title>\n    <credits><actor guest=\"true\">A</actor></credits>",
+			line:      6, col: 14,
+			path: "/tv/programme[1]/credits[1]/actor[1]/@guest",
+			err:  ErrInvalidValue,
+		},
+		{
+			name:      "bool element",
+			programme: "<title>News</title>\n    <video><colour>maybe</colour></video>",
+			line:      6, col: 12,
+			path: "/tv/programme[1]/video[1]/colour[1]",
+			err:  ErrInvalidValue,
+		},
+		{
+			name:      "non-numeric icon width",
+			programme: `<title>News</title><icon src="a.png" width="wide"/>`,
+			line:      5, col: 24,
+			path:   "/tv/programme[1]/icon[1]/@width",
+			err:    ErrInvalidValue,
+			laxErr: true,
+		},
+		{
+			name:      "image size",
+			programme: `<title>News</title><image size="4">a.png</image>`,
+			line:      5, col: 24,
+			path: "/tv/programme[1]/image[1]/@size",
+			err:  ErrInvalidValue,
+		},
+		{
+			name:      "image orientation",
+			programme: `<title>News</title><image orient="X">a.png</image>`,
+			line:      5, col: 24,
+			path: "/tv/programme[1]/image[1]/@orient",
+			err:  ErrInvalidValue,
+		},
+		{
+			name:      "unexpected text",
+			programme: `<title>News</title><video>HD</video>`,
+			line:      5, col: 24,
+			path: "/tv/programme[1]/video[1]",
+			err:  ErrInvalidValue,
+		},
+		{
+			name:      "programme attribute",
+			attrs:     ` tvg-id="x"`,
+			programme: `<title>News</title>`,
+			line:      4, col: 3,
+			path: "/tv/programme[1]/@tvg-id",
+			err:  ErrUnknownAttribute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			doc := "<?xml version=\"1.0\"?>\n<tv>\n  <channel id=\"one\"><display-name>One</display-name></channel>\n" +
+				"  <programme start=\"20220331180000 +0000\" channel=\"one\"" + tt.attrs + ">\n    " + tt.programme + "\n  </programme>\n</tv>\n"
+
+			d := NewDecoder(strings.NewReader(doc))
+			d.SetMode(DecodeModeStrict)
+
+			var tv TV
+			err := d.Decode(&tv)
+
+			var derr *DecodeError
+			if !errors.As(err, &derr) {
+				t.Fatalf("got error %v, want a *DecodeError", err)
+			}
+
+			if !errors.Is(err, tt.err) {
+				t.Errorf("got error %v, want %v", err, tt.err)
+			}
+
+			if derr.Line != tt.line || derr.Column != tt.col || derr.Path != tt.path {
+				t.Errorf("got %d:%d %s, want %d:%d %s", derr.Line, derr.Column, derr.Path, tt.line, tt.col, tt.path)
+			}
+
+			if err := NewDecoder(strings.NewReader(doc)).Decode(&tv); (err != nil) != tt.laxErr {
+				t.Errorf("default mode: got error %v, want error %t", err, tt.laxErr)
+			}
+		})
+	}
+}
+
+func TestDecoderStrictRoot(t *testing.T) {
+	t.Parallel()
+
+	for _, doc := range []string{
+		`<tv tvg-url="x"></tv>`,
+		`<tv><extra/></tv>`,
+	} {
+		d := NewDecoder(strings.NewReader(doc))
+		d.SetMode(DecodeModeStrict)
+
+		var tv TV
+		if err := d.Decode(&tv); !errors.Is(err, ErrUnknownAttribute) && !errors.Is(err, ErrUnknownElement) {
+			t.Errorf("%s: got error %v, want an unknown attribute or element", doc, err)
+		}
+	}
+}
@@ -3,15 +3,44 @@ package xmltv
 import (
 	"encoding/xml"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 )
 
-type Time struct{ time.Time }
+// Time is an XMLTV date/time value.
+type Time struct {
+	time.Time
+	// Layout is the layout the value was parsed from, one of the DTD-permitted
+	// layouts such as "20060102150405 -0700" or "2006". It is used when marshalling
+	// so that a value keeps its original precision and timezone offset. When empty,
+	// attributes are marshalled as "20060102150405 -0700" and elements as
+	// "20060102".
+	Layout string
+}
+
+// Precision is the granularity of a Time value.
+type Precision int
+
+const (
+	PrecisionYear Precision = iota + 1
+	PrecisionMonth
+	PrecisionDay
+	PrecisionHour
+	PrecisionMinute
+	PrecisionSecond
+)
+
+const (
+	defaultAttrLayout    = "20060102150405 -0700"
+	defaultElementLayout = "20060102"
+)
 
 // timeLayouts lists the accepted XMLTV date/time layouts, ordered from most to
 // least specific. Per the XMLTV DTD, dates are 'YYYYMMDDhhmmss' or any initial
 // substring (e.g. 'YYYYMM'), optionally followed by a numeric timezone offset.
-// If no explicit timezone is given, UTC is assumed.
+// If no explicit timezone is given, UTC is assumed; TV.AugmentTimeZones can
+// reinterpret such times in a channel's local timezone.
 var timeLayouts = []string{
 	"20060102150405 -0700",
 	"20060102150405",
@@ -27,15 +56,65 @@ var timeLayouts = []string{
 	"2006",
 }
 
-// parseTimeValue parses value against the accepted XMLTV layouts.
-func parseTimeValue(value string) (time.Time, error) {
+// parseTimeValue parses value against the accepted XMLTV layouts, recording the
+// layout that matched.
+func parseTimeValue(value string) (Time, error) {
 	for _, layout := range timeLayouts {
 		if t, err := time.Parse(layout, value); err == nil {
-			return t, nil
+			return Time{Time: t, Layout: layout}, nil
 		}
 	}
 
-	return time.Time{}, fmt.Errorf("xmltv: unable to parse time %q", value)
+	return Time{}, fmt.Errorf("xmltv: unable to parse time %q", value)
+}
+
+// Precision reports the granularity of t's Layout. A Time with an empty Layout
+// reports PrecisionSecond, the precision of the default attribute layout. It
+// describes attributes only: the same Time marshalled as an element such as
+// <date> is written with day precision.
+func (t Time) Precision() Precision {
+	if t.Layout == "" {
+		return PrecisionSecond
+	}
+
+	digits, _, _ := strings.Cut(t.Layout, " ")
+
+	switch len(digits) {
+	case 4:
+		return PrecisionYear
+	case 6:
+		return PrecisionMonth
+	case 8:
+		return PrecisionDay
+	case 10:
+		return PrecisionHour
+	case 12:
+		return PrecisionMinute
+	default:
+		return PrecisionSecond
+	}
+}
+
+// HasOffset reports whether t's Layout includes a timezone offset. A Time with an
+// empty Layout reports true, as the default attribute layout does. It describes
+// attributes only: the same Time marshalled as an element such as <date> is
+// written without an offset.
+func (t Time) HasOffset() bool {
+	return t.Layout == "" || strings.HasSuffix(t.Layout, " -0700")
+}
+
+// format formats t using its Layout, or defaultLayout if Layout is empty.
+func (t *Time) format(defaultLayout string) (string, error) {
+	layout := t.Layout
+	if layout == "" {
+		layout = defaultLayout
+	}
+
+	if !slices.Contains(timeLayouts, layout) {
+		return "", fmt.Errorf("xmltv: unsupported time layout %q", layout)
+	}
+
+	return t.Format(layout), nil
 }
 
 func (t *Time) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
@@ -47,7 +126,12 @@ func (t *Time) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
 		return xml.Attr{}, nil
 	}
 
-	return xml.Attr{Name: name, Value: t.Format("20060102150405 -0700")}, nil
+	value, err := t.format(defaultAttrLayout)
+	if err != nil {
+		return xml.Attr{}, err
+	}
+
+	return xml.Attr{Name: name, Value: value}, nil
 }
 
 func (t *Time) UnmarshalXMLAttr(attr xml.Attr) error {
@@ -62,7 +146,7 @@ func (t *Time) UnmarshalXMLAttr(attr xml.Attr) error {
 		return err
 	}
 
-	*t = Time{tt}
+	*t = tt
 
 	return nil
 }
@@ -76,7 +160,12 @@ func (t *Time) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 		return e.EncodeElement("", start)
 	}
 
-	return e.EncodeElement(t.Format("20060102"), start)
+	value, err := t.format(defaultElementLayout)
+	if err != nil {
+		return err
+	}
+
+	return e.EncodeElement(value, start)
 }
 
 func (t *Time) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
@@ -96,7 +185,71 @@ func (t *Time) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 		return err
 	}
 
-	*t = Time{tt}
+	*t = tt
 
 	return nil
 }
+
+// TimeFormat controls how programme times are marshalled by an Encoder.
+type TimeFormat struct {
+	// Layout is the DTD-permitted layout every time is written with, such as
+	// "200601021504 -0700". When empty, each value keeps its own Layout.
+	Layout string
+	// Location, when non-nil, is the location every time is converted into
+	// before being written. A layout without a timezone offset then has one
+	// appended, so that the converted wall-clock time is not read back as UTC.
+	Location *time.Location
+}
+
+// validate reports whether f's Layout is permitted by the DTD.
+func (f TimeFormat) validate() error {
+	if f.Layout != "" && !slices.Contains(timeLayouts, f.Layout) {
+		return fmt.Errorf("xmltv: unsupported time layout %q", f.Layout)
+	}
+
+	return nil
+}
+
+// apply returns a copy of t converted to f.
+func (f TimeFormat) apply(t Time) Time {
+	if f.Location != nil {
+		t.Time = t.In(f.Location)
+	}
+
+	if f.Layout != "" {
+		t.Layout = f.Layout
+	}
+
+	if f.Location != nil && !t.HasOffset() {
+		t.Layout += " -0700"
+	}
+
+	return t
+}
+
+// applyPtr is like apply for optional values, returning a new pointer so that
+// the original value is left untouched.
+func (f TimeFormat) applyPtr(t *Time) *Time {
+	if t == nil {
+		return nil
+	}
+
+	formatted := f.apply(*t)
+
+	return &formatted
+}
+
+// applyProgramme converts the Start, Stop, PDCStart, VPSStart and
+// PreviouslyShown.Start times of p to f.
+func (f TimeFormat) applyProgramme(p *Programme) {
+	p.Start = f.apply(p.Start)
+	p.Stop = f.applyPtr(p.Stop)
+	p.PDCStart = f.applyPtr(p.PDCStart)
+	p.VPSStart = f.applyPtr(p.VPSStart)
+
+	if p.PreviouslyShown != nil {
+		shown := *p.PreviouslyShown
+		shown.Start = f.applyPtr(shown.Start)
+		p.PreviouslyShown = &shown
+	}
+}
@@ -0,0 +1,158 @@
+package xmltv
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeZones maps channels to the location in which their times without an
+// explicit offset were published.
+type TimeZones struct {
+	// Default is the location of channels missing from Channels. When nil, the
+	// times of those channels are left untouched.
+	Default *time.Location
+	// Channels maps a Channel.ID to its location.
+	Channels map[string]*time.Location
+}
+
+// TimeZoneIssueKind is the kind of DST problem reported by a TimeZoneIssue.
+type TimeZoneIssueKind string
+
+const (
+	// TimeZoneIssueNonexistent reports a local time skipped when clocks go
+	// forward. The time is interpreted with the offset in effect before the
+	// transition, which moves it forward by the size of the gap.
+	TimeZoneIssueNonexistent TimeZoneIssueKind = "nonexistent"
+	// TimeZoneIssueAmbiguous reports a local time repeated when clocks go back.
+	// The earlier of the two instants is used.
+	TimeZoneIssueAmbiguous TimeZoneIssueKind = "ambiguous"
+)
+
+// TimeZoneIssue describes a programme time that does not map to exactly one
+// instant in its channel's location.
+type TimeZoneIssue struct {
+	Kind TimeZoneIssueKind `json:"kind"`
+	// Programme is the index of the programme in TV.Programmes, or in decoding
+	// order when reported by a Decoder.
+	Programme int    `json:"programme"`
+	Channel   string `json:"channel"`
+	// Attribute is the name of the affected attribute, such as "start", or
+	// "previously-shown/@start" for the start of PreviouslyShown.
+	Attribute string `json:"attribute"`
+	// Value is the time as it appeared in the document.
+	Value string `json:"value"`
+	// Resolved is the instant the time was interpreted as.
+	Resolved time.Time `json:"resolved"`
+}
+
+func (i TimeZoneIssue) String() string {
+	return fmt.Sprintf("programme %d on %q: %s time %s=%q resolved to %s",
+		i.Programme, i.Channel, i.Kind, i.Attribute, i.Value, i.Resolved.Format(defaultAttrLayout))
+}
+
+// AugmentTimeZones reinterprets the programme times that were parsed without an
+// explicit offset, which the package otherwise treats as UTC, as local times in
+// the location zones assigns to their channel. Times that carry an offset are
+// left untouched. Augmented times keep their precision and gain an offset in
+// their Layout. The start of a PreviouslyShown is interpreted in the location of
+// the channel it names, or of the programme's channel if it names none.
+//
+// Like the tv_augment_tz tool, it reports local times that do not exist or are
+// ambiguous because of a DST transition.
+func (tv *TV) AugmentTimeZones(zones TimeZones) []TimeZoneIssue {
+	var issues []TimeZoneIssue
+
+	for i := range tv.Programmes {
+		issues = append(issues, zones.augmentProgramme(&tv.Programmes[i], i)...)
+	}
+
+	return issues
+}
+
+// location returns the location of channel, or nil if it has none.
+func (z TimeZones) location(channel string) *time.Location {
+	if loc, ok := z.Channels[channel]; ok {
+		return loc
+	}
+
+	return z.Default
+}
+
+// augmentProgramme reinterprets the offset-less times of p, the index-th
+// programme, in the location of its channel. PreviouslyShown.Start is
+// reinterpreted in the location of the channel it names, or of p's channel if
+// it names none.
+func (z TimeZones) augmentProgramme(p *Programme, index int) []TimeZoneIssue {
+	var issues []TimeZoneIssue
+
+	augment := func(attribute string, t *Time, loc *time.Location) {
+		if loc == nil || t == nil || t.IsZero() || t.HasOffset() {
+			return
+		}
+
+		value := t.Format(t.Layout)
+
+		resolved, kind := localTime(t.Time, loc)
+		*t = Time{Time: resolved, Layout: t.Layout + " -0700"}
+
+		if kind != "" {
+			issues = append(issues, TimeZoneIssue{
+				Kind:      kind,
+				Programme: index,
+				Channel:   p.Channel,
+				Attribute: attribute,
+				Value:     value,
+				Resolved:  resolved,
+			})
+		}
+	}
+
+	loc := z.location(p.Channel)
+
+	augment("start", &p.Start, loc)
+	augment("stop", p.Stop, loc)
+	augment("pdc-start", p.PDCStart, loc)
+	augment("vps-start", p.VPSStart, loc)
+
+	if p.PreviouslyShown != nil {
+		shownLoc := loc
+		if p.PreviouslyShown.Channel != nil {
+			shownLoc = z.location(*p.PreviouslyShown.Channel)
+		}
+
+		augment("previously-shown/@start", p.PreviouslyShown.Start, shownLoc)
+	}
+
+	return issues
+}
+
+// localTime interprets the wall clock of naive, a time in UTC, in loc. It reports
+// whether that wall clock is skipped or repeated by a DST transition.
+func localTime(naive time.Time, loc *time.Location) (time.Time, TimeZoneIssueKind) {
+	// The offsets a day either side of the wall clock bracket any transition
+	// that could affect it.
+	_, before := naive.Add(-24 * time.Hour).In(loc).Zone()
+	_, after := naive.Add(24 * time.Hour).In(loc).Zone()
+
+	var candidates []time.Time
+
+	for _, offset := range []int{before, after} {
+		t := naive.Add(-time.Duration(offset) * time.Second).In(loc)
+		if _, o := t.Zone(); o == offset && (len(candidates) == 0 || !candidates[0].Equal(t)) {
+			candidates = append(candidates, t)
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return naive.Add(-time.Duration(before) * time.Second).In(loc), TimeZoneIssueNonexistent
+	case 1:
+		return candidates[0], ""
+	default:
+		if candidates[1].Before(candidates[0]) {
+			candidates[0] = candidates[1]
+		}
+
+		return candidates[0], TimeZoneIssueAmbiguous
+	}
+}
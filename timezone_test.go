@@ -0,0 +1,141 @@
+package xmltv
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const timeZonesDoc = `<tv>
+	<programme start="20220601200000" stop="20220601210000 +0000" channel="uk"><title>Summer</title></programme>
+	<programme start="20220327013000" channel="uk"><title>Spring forward</title></programme>
+	<programme start="202210300130" channel="uk"><title>Fall back</title></programme>
+	<programme start="20220601200000" channel="other"><title>Untouched</title></programme>
+</tv>`
+
+func loadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skip(err)
+	}
+
+	return loc
+}
+
+func TestAugmentTimeZones(t *testing.T) {
+	t.Parallel()
+
+	london := loadLocation(t, "Europe/London")
+
+	var tv TV
+	if err := xml.Unmarshal([]byte(timeZonesDoc), &tv); err != nil {
+		t.Fatal(err)
+	}
+
+	issues := tv.AugmentTimeZones(TimeZones{Channels: map[string]*time.Location{"uk": london}})
+
+	wantStarts := []string{
+		"20220601190000 +0000",
+		"20220327013000 +0000",
+		"20221030003000 +0000",
+		"20220601200000 +0000",
+	}
+	for i, want := range wantStarts {
+		if got := tv.Programmes[i].Start.UTC().Format(defaultAttrLayout); got != want {
+			t.Errorf("programme %d: got start %s, want %s", i, got, want)
+		}
+	}
+
+	if got := tv.Programmes[0].Stop.UTC().Format(defaultAttrLayout); got != "20220601210000 +0000" {
+		t.Errorf("stop with explicit offset was changed to %s", got)
+	}
+
+	if diff := cmp.Diff("200601021504 -0700", tv.Programmes[2].Start.Layout); diff != "" {
+		t.Error(diff)
+	}
+
+	if !tv.Programmes[3].Start.Equal(parseTime(t, "20060102150405", "20220601200000")) {
+		t.Errorf("programme on channel without location was changed to %v", tv.Programmes[3].Start)
+	}
+
+	got := make([]TimeZoneIssueKind, len(issues))
+	for i, issue := range issues {
+		got[i] = issue.Kind
+	}
+
+	if diff := cmp.Diff([]TimeZoneIssueKind{TimeZoneIssueNonexistent, TimeZoneIssueAmbiguous}, got); diff != "" {
+		t.Fatal(diff)
+	}
+
+	if issues[0].Programme != 1 || issues[0].Value != "20220327013000" {
+		t.Fatalf("unexpected issue %v", issues[0])
+	}
+}
+
+func TestAugmentTimeZonesPreviouslyShown(t *testing.T) {
+	t.Parallel()
+
+	london := loadLocation(t, "Europe/London")
+	newYork := loadLocation(t, "America/New_York")
+
+	doc := `<tv>
+	<programme start="20220601200000" channel="uk"><title>Own channel</title>` +
+		`<previously-shown start="20220501200000"/></programme>
+	<programme start="20220601200000" channel="uk"><title>Other channel</title>` +
+		`<previously-shown start="20220501200000" channel="us"/></programme>
+	<programme start="20220601200000" channel="other"><title>From a zoned channel</title>` +
+		`<previously-shown start="20220501200000" channel="uk"/></programme>
+</tv>`
+
+	var tv TV
+	if err := xml.Unmarshal([]byte(doc), &tv); err != nil {
+		t.Fatal(err)
+	}
+
+	tv.AugmentTimeZones(TimeZones{Channels: map[string]*time.Location{"uk": london, "us": newYork}})
+
+	want := []string{"20220501190000 +0000", "20220502000000 +0000", "20220501190000 +0000"}
+
+	got := make([]string, len(tv.Programmes))
+	for i, p := range tv.Programmes {
+		got[i] = p.PreviouslyShown.Start.UTC().Format(defaultAttrLayout)
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestDecoderTimeZones(t *testing.T) {
+	t.Parallel()
+
+	london := loadLocation(t, "Europe/London")
+
+	d := NewDecoder(strings.NewReader(timeZonesDoc))
+	d.SetTimeZones(TimeZones{Default: london})
+
+	var got TV
+	if err := d.Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	var want TV
+	if err := xml.Unmarshal([]byte(timeZonesDoc), &want); err != nil {
+		t.Fatal(err)
+	}
+
+	wantIssues := want.AugmentTimeZones(TimeZones{Default: london})
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatal(diff)
+	}
+
+	if diff := cmp.Diff(wantIssues, d.TimeZoneIssues()); diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -0,0 +1,273 @@
+package xmltv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ValidationCode identifies the kind of DTD violation reported by a
+// ValidationError.
+type ValidationCode string
+
+const (
+	// ValidationCodeRequired reports a missing required element, attribute or
+	// value.
+	ValidationCodeRequired ValidationCode = "required"
+	// ValidationCodeInvalidValue reports a value outside the set permitted by the
+	// DTD.
+	ValidationCodeInvalidValue ValidationCode = "invalid-value"
+)
+
+// ValidationError describes a single DTD violation. Path addresses the offending
+// value, e.g. "programmes[412].titles".
+type ValidationError struct {
+	Path    string         `json:"path"`
+	Code    ValidationCode `json:"code"`
+	Message string         `json:"message"`
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("xmltv: %s: %s", e.Path, e.Message)
+}
+
+// ValidationErrors is the list of every violation found by TV.Validate.
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "xmltv: %d validation errors", len(e))
+
+	for _, err := range e {
+		fmt.Fprintf(&b, "\n\t%s: %s", err.Path, err.Message)
+	}
+
+	return b.String()
+}
+
+func (e ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+
+	return errs
+}
+
+// Validate checks tv against the constraints of the XMLTV DTD that the Go types
+// cannot express, such as required child elements and enumerated attribute
+// values. It returns nil if tv conforms, and otherwise a ValidationErrors holding
+// every violation found.
+func (tv *TV) Validate() error {
+	var v validator
+
+	for i := range tv.Channels {
+		v.channel(fmt.Sprintf("channels[%d]", i), &tv.Channels[i])
+	}
+
+	for i := range tv.Programmes {
+		v.programme(fmt.Sprintf("programmes[%d]", i), &tv.Programmes[i])
+	}
+
+	if len(v.errs) == 0 {
+		return nil
+	}
+
+	return v.errs
+}
+
+// validator accumulates the violations found while walking a TV.
+type validator struct {
+	errs ValidationErrors
+}
+
+func (v *validator) report(path string, code ValidationCode, format string, args ...any) {
+	v.errs = append(v.errs, &ValidationError{Path: path, Code: code, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) required(path string, present bool, what string) {
+	if !present {
+		v.report(path, ValidationCodeRequired, "missing %s", what)
+	}
+}
+
+func (v *validator) channel(path string, c *Channel) {
+	v.required(path+".id", c.ID != "", "id attribute")
+	v.required(path+".displayNames", len(c.DisplayNames) > 0, "display-name element")
+	v.icons(path+".icons", c.Icons)
+}
+
+func (v *validator) programme(path string, p *Programme) {
+	v.required(path+".start", !p.Start.IsZero(), "start attribute")
+	v.required(path+".channel", p.Channel != "", "channel attribute")
+
+	if p.ClumpIndex != nil {
+		if _, _, ok := parseClumpIndex(*p.ClumpIndex); !ok {
+			v.report(path+".clumpIndex", ValidationCodeInvalidValue, "clumpidx %q is not of the form 'n/m' with n < m", *p.ClumpIndex)
+		}
+	}
+
+	v.required(path+".titles", len(p.Titles) > 0, "title element")
+
+	if p.Credits != nil {
+		v.credits(path+".credits", p.Credits)
+	}
+
+	if p.Length != nil {
+		v.length(path+".length", p.Length)
+	}
+
+	v.icons(path+".icons", p.Icons)
+
+	for i, s := range p.Subtitles {
+		if s.Type != nil {
+			switch *s.Type {
+			case SubtitlesTypeTeletext, SubtitlesTypeOnScreen, SubtitlesTypeDeafSigned:
+			default:
+				v.report(fmt.Sprintf("%s.subtitles[%d].type", path, i), ValidationCodeInvalidValue, "unknown subtitles type %q", *s.Type)
+			}
+		}
+	}
+
+	for i, r := range p.Ratings {
+		rPath := fmt.Sprintf("%s.ratings[%d]", path, i)
+		v.required(rPath+".value", r.Value != nil, "value element")
+		v.icons(rPath+".icons", r.Icons)
+	}
+
+	for i, r := range p.StarRatings {
+		rPath := fmt.Sprintf("%s.starRatings[%d]", path, i)
+		v.required(rPath+".value", r.Value != nil, "value element")
+		v.icons(rPath+".icons", r.Icons)
+	}
+
+	for i, r := range p.Reviews {
+		rPath := fmt.Sprintf("%s.reviews[%d].type", path, i)
+		if r.Type == nil {
+			v.report(rPath, ValidationCodeRequired, "missing type attribute")
+
+			continue
+		}
+
+		switch *r.Type {
+		case ReviewTypeText, ReviewTypeURL:
+		default:
+			v.report(rPath, ValidationCodeInvalidValue, "unknown review type %q", *r.Type)
+		}
+	}
+
+	v.images(path+".images", p.Images)
+}
+
+func (v *validator) credits(path string, c *Credits) {
+	for i, a := range c.Actors {
+		v.images(fmt.Sprintf("%s.actors[%d].images", path, i), a.Images)
+	}
+
+	for _, people := range []struct {
+		name   string
+		images [][]Image
+	}{
+		{"directors", imagesOf(c.Directors, func(p Director) []Image { return p.Images })},
+		{"writers", imagesOf(c.Writers, func(p Writer) []Image { return p.Images })},
+		{"adapters", imagesOf(c.Adapters, func(p Adapter) []Image { return p.Images })},
+		{"producers", imagesOf(c.Producers, func(p Producer) []Image { return p.Images })},
+		{"composers", imagesOf(c.Composers, func(p Composer) []Image { return p.Images })},
+		{"editors", imagesOf(c.Editors, func(p Editor) []Image { return p.Images })},
+		{"presenters", imagesOf(c.Presenters, func(p Presenter) []Image { return p.Images })},
+		{"commentators", imagesOf(c.Commentators, func(p Commentator) []Image { return p.Images })},
+		{"guests", imagesOf(c.Guests, func(p Guest) []Image { return p.Images })},
+	} {
+		for i, images := range people.images {
+			v.images(fmt.Sprintf("%s.%s[%d].images", path, people.name, i), images)
+		}
+	}
+}
+
+func (v *validator) length(path string, l *Length) {
+	switch l.Units {
+	case LengthUnitsSeconds, LengthUnitsMinutes, LengthUnitsHours:
+	case "":
+		v.report(path+".units", ValidationCodeRequired, "missing units attribute")
+	default:
+		v.report(path+".units", ValidationCodeInvalidValue, "unknown length units %q", l.Units)
+	}
+
+	v.required(path+".text", l.Text != nil, "length value")
+}
+
+func (v *validator) icons(path string, icons []Icon) {
+	for i, icon := range icons {
+		v.required(fmt.Sprintf("%s[%d].source", path, i), icon.Source != "", "src attribute")
+	}
+}
+
+func (v *validator) images(path string, images []Image) {
+	for i, image := range images {
+		iPath := fmt.Sprintf("%s[%d]", path, i)
+
+		if image.Type != nil {
+			switch *image.Type {
+			case ImageTypePoster, ImageTypeBackdrop, ImageTypeStill, ImageTypePerson, ImageTypeCharacter:
+			default:
+				v.report(iPath+".type", ValidationCodeInvalidValue, "unknown image type %q", *image.Type)
+			}
+		}
+
+		if image.Size != nil {
+			switch *image.Size {
+			case ImageSizeSmall, ImageSizeMedium, ImageSizeLarge:
+			default:
+				v.report(iPath+".size", ValidationCodeInvalidValue, "unknown image size %d", *image.Size)
+			}
+		}
+
+		if image.Orientation != nil {
+			switch *image.Orientation {
+			case ImageOrientationPortrait, ImageOrientationLandscape:
+			default:
+				v.report(iPath+".orientation", ValidationCodeInvalidValue, "unknown image orientation %q", *image.Orientation)
+			}
+		}
+	}
+}
+
+// imagesOf returns the images of each credited person.
+func imagesOf[T any](people []T, images func(T) []Image) [][]Image {
+	out := make([][]Image, len(people))
+	for i, p := range people {
+		out[i] = images(p)
+	}
+
+	return out
+}
+
+// parseClumpIndex parses a clumpidx attribute of the form 'n/m', where the
+// programme is the n-th (zero-based) of m programmes sharing a time slot.
+func parseClumpIndex(value string) (index, count int, ok bool) {
+	i, c, found := strings.Cut(value, "/")
+	if !found {
+		return 0, 0, false
+	}
+
+	index, err := strconv.Atoi(strings.TrimSpace(i))
+	if err != nil {
+		return 0, 0, false
+	}
+
+	count, err = strconv.Atoi(strings.TrimSpace(c))
+	if err != nil {
+		return 0, 0, false
+	}
+
+	if index < 0 || count < 1 || index >= count {
+		return 0, 0, false
+	}
+
+	return index, count, true
+}
@@ -0,0 +1,79 @@
+package xmltv
+
+import (
+	"encoding/xml"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	data, err := os.ReadFile("testdata/unmarshal/epg.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tv TV
+	if err := xml.Unmarshal(data, &tv); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tv.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateViolations(t *testing.T) {
+	t.Parallel()
+
+	tv := TV{
+		Channels: []Channel{
+			{ID: "channel-one.tv"},
+		},
+		Programmes: []Programme{
+			{
+				Start:   Time{Time: parseTime(t, "20060102150405 -0700", "20220331180000 +0000")},
+				Channel: "channel-one.tv",
+				Titles:  []Title{{Text: "Programme One"}},
+			},
+			{
+				Start:      Time{Time: parseTime(t, "20060102150405 -0700", "20220331190000 +0000")},
+				Channel:    "channel-one.tv",
+				ClumpIndex: makePointer("1/1"),
+				Length:     &Length{Units: "days"},
+				Subtitles:  []Subtitles{{Type: makePointer(SubtitlesType("closed"))}},
+				Ratings:    []Rating{{Icons: []Icon{{}}}},
+				Reviews:    []Review{{Text: "Great"}},
+				Images:     []Image{{Size: makePointer(ImageSize(4))}},
+			},
+		},
+	}
+
+	err := tv.Validate()
+
+	var got ValidationErrors
+	if !errors.As(err, &got) {
+		t.Fatalf("got %v, want ValidationErrors", err)
+	}
+
+	want := ValidationErrors{
+		{Path: "channels[0].displayNames", Code: ValidationCodeRequired, Message: "missing display-name element"},
+		{Path: "programmes[1].clumpIndex", Code: ValidationCodeInvalidValue, Message: `clumpidx "1/1" is not of the form 'n/m' with n < m`},
+		{Path: "programmes[1].titles", Code: ValidationCodeRequired, Message: "missing title element"},
+		{Path: "programmes[1].length.units", Code: ValidationCodeInvalidValue, Message: `unknown length units "days"`},
+		{Path: "programmes[1].length.text", Code: ValidationCodeRequired, Message: "missing length value"},
+		{Path: "programmes[1].subtitles[0].type", Code: ValidationCodeInvalidValue, Message: `unknown subtitles type "closed"`},
+		{Path: "programmes[1].ratings[0].value", Code: ValidationCodeRequired, Message: "missing value element"},
+		{Path: "programmes[1].ratings[0].icons[0].source", Code: ValidationCodeRequired, Message: "missing src attribute"},
+		{Path: "programmes[1].reviews[0].type", Code: ValidationCodeRequired, Message: "missing type attribute"},
+		{Path: "programmes[1].images[0].size", Code: ValidationCodeInvalidValue, Message: "unknown image size 4"},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -0,0 +1,61 @@
+package xmltv
+
+import (
+	"slices"
+	"time"
+)
+
+// WindowMode selects which programmes TV.Window keeps.
+type WindowMode int
+
+const (
+	// WindowModeOverlap keeps programmes that are on air at any time in
+	// [from, to).
+	WindowModeOverlap WindowMode = iota
+	// WindowModeStart keeps programmes that start in [from, to).
+	WindowModeStart
+	// WindowModeStop keeps programmes that stop in (from, to], so that a
+	// programme ending exactly at to is kept and one ending exactly at from is
+	// not.
+	WindowModeStop
+)
+
+// Window removes the programmes outside the window between from and to, as
+// selected by mode, and sets the root Date to the current time. Programmes
+// without a stop time are taken to stop when the next programme on the same
+// channel starts. Channels are kept even when left without programmes; use
+// PruneChannels to drop them.
+func (tv *TV) Window(from, to time.Time, mode WindowMode) {
+	idx := newStartIndex(tv.Programmes)
+
+	tv.Programmes = slices.DeleteFunc(tv.Programmes, func(p Programme) bool {
+		start, stop := p.Start.Time, idx.stop(&p)
+
+		switch mode {
+		case WindowModeStart:
+			return start.Before(from) || !start.Before(to)
+		case WindowModeStop:
+			return !stop.After(from) || stop.After(to)
+		default:
+			if !stop.After(start) {
+				return start.Before(from) || !start.Before(to)
+			}
+
+			return !start.Before(to) || !stop.After(from)
+		}
+	})
+
+	tv.Date = &Time{Time: time.Now()}
+}
+
+// PruneChannels removes the channels that have no programmes.
+func (tv *TV) PruneChannels() {
+	used := make(map[string]bool)
+	for _, p := range tv.Programmes {
+		used[p.Channel] = true
+	}
+
+	tv.Channels = slices.DeleteFunc(tv.Channels, func(c Channel) bool {
+		return !used[c.ID]
+	})
+}
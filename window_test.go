@@ -0,0 +1,110 @@
+package xmltv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWindow(t *testing.T) {
+	t.Parallel()
+
+	from := parseTime(t, "200601021504", "202203311900")
+	to := parseTime(t, "200601021504", "202203312100")
+
+	tests := []struct {
+		name string
+		mode WindowMode
+		want []string
+	}{
+		{name: "overlap", mode: WindowModeOverlap, want: []string{"Before overlap", "Inside", "Open ended", "After overlap", "Other channel"}},
+		{name: "start", mode: WindowModeStart, want: []string{"Inside", "Open ended", "After overlap"}},
+		{name: "stop", mode: WindowModeStop, want: []string{"Before overlap", "Inside", "Open ended", "Other channel"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tv := TV{
+				Channels: []Channel{{ID: "one"}, {ID: "two"}},
+				Programmes: []Programme{
+					newProgramme(t, "one", "202203311700", "202203311800", "Before"),
+					newProgramme(t, "one", "202203311800", "202203311930", "Before overlap"),
+					newProgramme(t, "one", "202203311930", "202203312000", "Inside"),
+					newProgramme(t, "one", "202203312000", "", "Open ended"),
+					newProgramme(t, "one", "202203312030", "202203312200", "After overlap"),
+					newProgramme(t, "one", "202203312200", "", "After"),
+					newProgramme(t, "two", "202203311700", "202203311930", "Other channel"),
+				},
+			}
+
+			before := time.Now()
+			tv.Window(from, to, tt.mode)
+			after := time.Now()
+
+			if diff := cmp.Diff(tt.want, programmeTitles(tv.Programmes)); diff != "" {
+				t.Fatal(diff)
+			}
+
+			if tv.Date == nil || tv.Date.Before(before) || tv.Date.After(after) {
+				t.Fatalf("got Date %v, want the time of the call", tv.Date)
+			}
+		})
+	}
+}
+
+func TestWindowBounds(t *testing.T) {
+	t.Parallel()
+
+	from := parseTime(t, "200601021504", "202203311900")
+	to := parseTime(t, "200601021504", "202203312100")
+
+	tests := []struct {
+		name string
+		mode WindowMode
+		want []string
+	}{
+		{name: "overlap", mode: WindowModeOverlap, want: []string{"Starts at from", "Stops at to", "Spans"}},
+		{name: "start", mode: WindowModeStart, want: []string{"Starts at from", "Stops at to"}},
+		{name: "stop", mode: WindowModeStop, want: []string{"Starts at from", "Stops at to", "Spans"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tv := TV{
+				Programmes: []Programme{
+					newProgramme(t, "one", "202203311800", "202203311900", "Stops at from"),
+					newProgramme(t, "one", "202203311900", "202203311930", "Starts at from"),
+					newProgramme(t, "two", "202203312000", "202203312100", "Stops at to"),
+					newProgramme(t, "two", "202203312100", "202203312130", "Starts at to"),
+					newProgramme(t, "three", "202203311800", "202203312100", "Spans"),
+				},
+			}
+
+			tv.Window(from, to, tt.mode)
+
+			if diff := cmp.Diff(tt.want, programmeTitles(tv.Programmes)); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestPruneChannels(t *testing.T) {
+	t.Parallel()
+
+	tv := TV{
+		Channels:   []Channel{{ID: "one"}, {ID: "two"}},
+		Programmes: []Programme{newProgramme(t, "two", "202203311700", "", "Two")},
+	}
+
+	tv.PruneChannels()
+
+	if diff := cmp.Diff([]Channel{{ID: "two"}}, tv.Channels); diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -0,0 +1,142 @@
+package xmltv
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// EncodeOptions configures Marshal, Write and WriteFile.
+type EncodeOptions struct {
+	// Prefix and Indent are passed to Encoder.Indent. If both are empty, the
+	// document is written without indentation.
+	Prefix, Indent string
+	// DocType sets whether the DOCTYPE declaration is written.
+	DocType bool
+	// Stylesheet is the href passed to Encoder.SetStylesheet.
+	Stylesheet string
+	// Charset is the character set passed to Encoder.SetCharset. If empty, the
+	// document is written in UTF-8.
+	Charset string
+	// TimeFormat is passed to Encoder.SetTimeFormat. The zero TimeFormat writes
+	// each time as is.
+	TimeFormat TimeFormat
+	// GzipLevel is passed to Encoder.SetGzipLevel. If zero, the document is not
+	// compressed, so gzip.NoCompression cannot be requested.
+	GzipLevel int
+}
+
+// newEncoder returns an encoder writing to w configured with opts.
+func (opts EncodeOptions) newEncoder(w io.Writer) (*Encoder, error) {
+	enc := NewEncoder(w)
+	enc.Indent(opts.Prefix, opts.Indent)
+	enc.SetDocType(opts.DocType)
+	enc.SetStylesheet(opts.Stylesheet)
+
+	if opts.Charset != "" {
+		if err := enc.SetCharset(opts.Charset); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := enc.SetTimeFormat(opts.TimeFormat); err != nil {
+		return nil, err
+	}
+
+	if opts.GzipLevel != 0 {
+		if err := enc.SetGzipLevel(opts.GzipLevel); err != nil {
+			return nil, err
+		}
+	}
+
+	return enc, nil
+}
+
+// Write writes tv to w as a complete document, starting with the XML
+// declaration.
+func Write(w io.Writer, tv *TV, opts EncodeOptions) error {
+	enc, err := opts.newEncoder(w)
+	if err != nil {
+		return err
+	}
+
+	return enc.Encode(tv)
+}
+
+// Marshal returns tv as a complete document, starting with the XML declaration.
+func Marshal(tv *TV, opts EncodeOptions) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := Write(&buf, tv, opts); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// WriteTo implements io.WriterTo. It writes tv as a complete document indented
+// by two spaces, with the XML declaration and the DOCTYPE declaration, as Write
+// does with EncodeOptions{Indent: "  ", DocType: true}. Use Write for other
+// options.
+func (tv *TV) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	err := Write(cw, tv, EncodeOptions{Indent: "  ", DocType: true})
+
+	return cw.n, err
+}
+
+// WriteFile writes tv to the named file as Write does; set GzipLevel in opts to
+// write a compressed file such as guide.xml.gz. The document is written
+// to a temporary file in the same directory, which is then renamed, so that
+// readers of the file never see a partly written guide. An existing file keeps
+// its permissions; a new one is created with mode 0644.
+func WriteFile(name string, tv *TV, opts EncodeOptions) (err error) {
+	mode := fs.FileMode(0o644)
+	if info, err := os.Stat(name); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+
+	if err := Write(f, tv, opts); err != nil {
+		return err
+	}
+
+	if err := f.Chmod(mode); err != nil {
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), name)
+}
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+
+	return n, err
+}
@@ -0,0 +1,197 @@
+package xmltv
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func writeTestTV(t *testing.T) *TV {
+	t.Helper()
+
+	return &TV{
+		GeneratorInfoName: makePointer("g"),
+		Channels:          []Channel{{ID: "one", DisplayNames: []DisplayName{{Text: "One"}}}},
+		Programmes:        []Programme{newProgramme(t, "one", "202203311800", "", "News")},
+	}
+}
+
+func TestMarshalOptions(t *testing.T) {
+	t.Parallel()
+
+	tv := writeTestTV(t)
+
+	tests := []struct {
+		name string
+		opts EncodeOptions
+		want string
+	}{
+		{
+			name: "zero",
+			want: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<tv generator-info-name="g"><channel id="one"><display-name>One</display-name></channel>` +
+				`<programme start="20220331180000 +0000" channel="one"><title>News</title></programme></tv>` + "\n",
+		},
+		{
+			name: "all",
+			opts: EncodeOptions{Indent: "\t", DocType: true, Stylesheet: "guide.xsl?a=1&b=2", Charset: "windows-1252"},
+			want: `<?xml version="1.0" encoding="windows-1252"?>` + "\n" +
+				`<?xml-stylesheet type="text/xsl" href="guide.xsl?a=1&amp;b=2"?>` + "\n" +
+				DocType + "\n" +
+				"<tv generator-info-name=\"g\">\n" +
+				"\t<channel id=\"one\">\n\t\t<display-name>One</display-name>\n\t</channel>\n" +
+				"\t<programme start=\"20220331180000 +0000\" channel=\"one\">\n\t\t<title>News</title>\n\t</programme>\n" +
+				"</tv>\n",
+		},
+		{
+			name: "css",
+			opts: EncodeOptions{Stylesheet: "guide.CSS"},
+			want: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<?xml-stylesheet type="text/css" href="guide.CSS"?>` + "\n" +
+				`<tv generator-info-name="g"><channel id="one"><display-name>One</display-name></channel>` +
+				`<programme start="20220331180000 +0000" channel="one"><title>News</title></programme></tv>` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Marshal(tv, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tt.want, string(got)); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+
+	if _, err := Marshal(tv, EncodeOptions{Charset: "EBCDIC"}); err == nil {
+		t.Error("got nil error for an unsupported charset")
+	}
+}
+
+func TestTVWriteTo(t *testing.T) {
+	t.Parallel()
+
+	tv := writeTestTV(t)
+
+	var buf bytes.Buffer
+
+	n, err := tv.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != int64(buf.Len()) {
+		t.Errorf("got %d bytes written, want %d", n, buf.Len())
+	}
+
+	want, err := Marshal(tv, EncodeOptions{Indent: "  ", DocType: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(string(want), buf.String()); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	t.Parallel()
+
+	tv := writeTestTV(t)
+	dir := t.TempDir()
+	name := filepath.Join(dir, "guide.xml")
+
+	if err := os.WriteFile(name, []byte("old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteFile(name, tv, EncodeOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := Marshal(tv, EncodeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(string(want), string(got)); diff != "" {
+		t.Error(diff)
+	}
+
+	info, err := os.Stat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("got mode %v, want the mode of the replaced file", info.Mode().Perm())
+	}
+
+	if err := WriteFile(name, tv, EncodeOptions{Charset: "EBCDIC"}); err == nil {
+		t.Error("got nil error for an unsupported charset")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 {
+		t.Errorf("got %d files, want the temporary file removed", len(entries))
+	}
+}
+
+func TestWriteFileGzip(t *testing.T) {
+	t.Parallel()
+
+	tv := writeTestTV(t)
+	name := filepath.Join(t.TempDir(), "guide.xml.gz")
+
+	if err := WriteFile(name, tv, EncodeOptions{GzipLevel: gzip.BestCompression}); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := Marshal(tv, EncodeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(string(want), string(got)); diff != "" {
+		t.Error(diff)
+	}
+
+	if err := WriteFile(name, tv, EncodeOptions{GzipLevel: 42}); err == nil {
+		t.Error("got nil error for an invalid gzip level")
+	}
+}
@@ -23,6 +23,10 @@ type TV struct {
 	GeneratorInfoURL  *string     `xml:"generator-info-url,attr,omitempty"`
 	Channels          []Channel   `xml:"channel,omitempty"`
 	Programmes        []Programme `xml:"programme,omitempty"`
+	// Attrs and Extensions hold the attributes and child elements not defined by
+	// the DTD.
+	Attrs      []Attr      `xml:",any,attr"`
+	Extensions []Extension `xml:",any"`
 }
 
 // Channel represents a channel.
@@ -32,6 +36,10 @@ type Channel struct {
 	DisplayNames []DisplayName `xml:"display-name"`
 	Icons        []Icon        `xml:"icon,omitempty"`
 	URLs         []URL         `xml:"url,omitempty"`
+	// Attrs and Extensions hold the attributes and child elements not defined by
+	// the DTD.
+	Attrs      []Attr      `xml:",any,attr"`
+	Extensions []Extension `xml:",any"`
 }
 
 type DisplayName struct {
@@ -89,6 +97,10 @@ type Programme struct {
 	StarRatings      []StarRating      `xml:"star-rating,omitempty"`
 	Reviews          []Review          `xml:"review,omitempty"`
 	Images           []Image           `xml:"image,omitempty"`
+	// Attrs and Extensions hold the attributes and child elements not defined by
+	// the DTD.
+	Attrs      []Attr      `xml:",any,attr"`
+	Extensions []Extension `xml:",any"`
 }
 
 type Title struct {
@@ -813,7 +813,8 @@ func TestUnmarshal(t *testing.T) {
 			Local: "tv",
 		},
 		Date: &Time{
-			Time: parseTime(t, "20060102150405 -0700", "20220401000000 +0000"),
+			Time:   parseTime(t, "20060102150405 -0700", "20220401000000 +0000"),
+			Layout: "20060102150405 -0700",
 		},
 		SourceInfoURL:     makePointer("example.com"),
 		SourceInfoName:    makePointer("example"),
@@ -902,16 +903,20 @@ func TestUnmarshal(t *testing.T) {
 					Local: "programme",
 				},
 				Start: Time{
-					Time: parseTime(t, "20060102150405 -0700", "20220331180000 +0000"),
+					Time:   parseTime(t, "20060102150405 -0700", "20220331180000 +0000"),
+					Layout: "20060102150405 -0700",
 				},
 				Stop: &Time{
-					Time: parseTime(t, "20060102150405 -0700", "20220331190000 +0000"),
+					Time:   parseTime(t, "20060102150405 -0700", "20220331190000 +0000"),
+					Layout: "20060102150405 -0700",
 				},
 				PDCStart: &Time{
-					Time: parseTime(t, "20060102150405 -0700", "20220331180000 +0000"),
+					Time:   parseTime(t, "20060102150405 -0700", "20220331180000 +0000"),
+					Layout: "20060102150405 -0700",
 				},
 				VPSStart: &Time{
-					Time: parseTime(t, "20060102150405 -0700", "20220331180000 +0000"),
+					Time:   parseTime(t, "20060102150405 -0700", "20220331180000 +0000"),
+					Layout: "20060102150405 -0700",
 				},
 				ShowView:   makePointer("12345"),
 				VideoPlus:  makePointer("67890"),
@@ -1132,7 +1137,8 @@ func TestUnmarshal(t *testing.T) {
 					},
 				},
 				Date: &Time{
-					Time: parseTime(t, "20060102", "19901011"),
+					Time:   parseTime(t, "20060102", "19901011"),
+					Layout: "20060102",
 				},
 				Categories: []Category{
 					{
@@ -1293,7 +1299,8 @@ func TestUnmarshal(t *testing.T) {
 						Local: "previously-shown",
 					},
 					Start: &Time{
-						Time: parseTime(t, "20060102150405 -0700", "20220331180000 +0000"),
+						Time:   parseTime(t, "20060102150405 -0700", "20220331180000 +0000"),
+						Layout: "20060102150405 -0700",
 					},
 					Channel: makePointer("channel-two.tv"),
 				},
@@ -1518,7 +1525,8 @@ func TestUnmarshal(t *testing.T) {
 					Local: "programme",
 				},
 				Start: Time{
-					Time: parseTime(t, "20060102150405 -0700", "20220331180000 +0000"),
+					Time:   parseTime(t, "20060102150405 -0700", "20220331180000 +0000"),
+					Layout: "20060102150405 -0700",
 				},
 				Stop:       nil,
 				PDCStart:   nil,
@@ -2446,16 +2454,20 @@ func TestUnmarshalEmptyDate(t *testing.T) {
 					Local: "programme",
 				},
 				Start: Time{
-					Time: parseTime(t, "20060102150405 -0700", "20220331180000 +0000"),
+					Time:   parseTime(t, "20060102150405 -0700", "20220331180000 +0000"),
+					Layout: "20060102150405 -0700",
 				},
 				Stop: &Time{
-					Time: parseTime(t, "20060102150405 -0700", "20220331190000 +0000"),
+					Time:   parseTime(t, "20060102150405 -0700", "20220331190000 +0000"),
+					Layout: "20060102150405 -0700",
 				},
 				PDCStart: &Time{
-					Time: parseTime(t, "20060102150405 -0700", "20220331180000 +0000"),
+					Time:   parseTime(t, "20060102150405 -0700", "20220331180000 +0000"),
+					Layout: "20060102150405 -0700",
 				},
 				VPSStart: &Time{
-					Time: parseTime(t, "20060102150405 -0700", "20220331180000 +0000"),
+					Time:   parseTime(t, "20060102150405 -0700", "20220331180000 +0000"),
+					Layout: "20060102150405 -0700",
 				},
 				ShowView:   makePointer("12345"),
 				VideoPlus:  makePointer("67890"),
@@ -2835,7 +2847,8 @@ func TestUnmarshalEmptyDate(t *testing.T) {
 						Local: "previously-shown",
 					},
 					Start: &Time{
-						Time: parseTime(t, "20060102150405 -0700", "20220331180000 +0000"),
+						Time:   parseTime(t, "20060102150405 -0700", "20220331180000 +0000"),
+						Layout: "20060102150405 -0700",
 					},
 					Channel: makePointer("channel-two.tv"),
 				},
@@ -3060,7 +3073,8 @@ func TestUnmarshalEmptyDate(t *testing.T) {
 					Local: "programme",
 				},
 				Start: Time{
-					Time: parseTime(t, "20060102150405 -0700", "20220331180000 +0000"),
+					Time:   parseTime(t, "20060102150405 -0700", "20220331180000 +0000"),
+					Layout: "20060102150405 -0700",
 				},
 				Stop:       nil,
 				PDCStart:   nil,
@@ -3176,3 +3190,70 @@ func TestUnmarshalInvalidTime(t *testing.T) {
 		t.Fatal("expected error parsing invalid date, got nil")
 	}
 }
+
+func TestMarshalPreservesTimePrecision(t *testing.T) {
+	t.Parallel()
+
+	doc := `<tv date="2004"><programme start="200407281730 +0300" stop="20040728190000" channel="a">` +
+		`<title>One</title><date>1994</date></programme></tv>`
+
+	var tv TV
+	if err := xml.Unmarshal([]byte(doc), &tv); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := xml.Marshal(tv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(doc, string(got)); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestTimePrecision(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value      string
+		want       Precision
+		wantOffset bool
+	}{
+		{value: "2004", want: PrecisionYear, wantOffset: false},
+		{value: "200407 +0000", want: PrecisionMonth, wantOffset: true},
+		{value: "20040728", want: PrecisionDay, wantOffset: false},
+		{value: "2004072817", want: PrecisionHour, wantOffset: false},
+		{value: "200407281730 -0500", want: PrecisionMinute, wantOffset: true},
+		{value: "20040728173000", want: PrecisionSecond, wantOffset: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+
+			var got Time
+			if err := got.UnmarshalXMLAttr(xml.Attr{Value: tt.value}); err != nil {
+				t.Fatal(err)
+			}
+
+			if got.Precision() != tt.want {
+				t.Fatalf("value %q: got precision %d, want %d", tt.value, got.Precision(), tt.want)
+			}
+
+			if got.HasOffset() != tt.wantOffset {
+				t.Fatalf("value %q: got offset %t, want %t", tt.value, got.HasOffset(), tt.wantOffset)
+			}
+		})
+	}
+}
+
+func TestMarshalInvalidTimeLayout(t *testing.T) {
+	t.Parallel()
+
+	tv := TV{Date: &Time{Time: time.Now(), Layout: time.RFC3339}}
+
+	if _, err := xml.Marshal(tv); err == nil {
+		t.Fatal("expected error marshalling unsupported layout, got nil")
+	}
+}